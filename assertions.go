@@ -1,15 +1,24 @@
 package got
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math"
+	"os"
 	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
+	"github.com/ysmood/got/lib/gop"
 	"github.com/ysmood/got/lib/utils"
 )
 
@@ -19,6 +28,10 @@ type Assertions struct {
 
 	ErrorHandler AssertionError
 
+	// EqualHook is consulted by Eq, Neq, and Equal before the default comparison,
+	// such as to use proto.Equal for the generated types. If handled is false, the default comparison will be used.
+	EqualHook func(x, y interface{}) (handled, equal bool)
+
 	must bool
 
 	desc string
@@ -40,17 +53,128 @@ func (as Assertions) Must() Assertions {
 
 // Eq asserts that x equals y when converted to the same type, such as compare float 1.0 and integer 1 .
 // For strict value and type comparison use Assertions.Equal .
+// A NaN never equals a NaN as float numbers, set Assertions.EqualHook to EqualNaN to treat them as equal.
 func (as Assertions) Eq(x, y interface{}) {
 	as.Helper()
-	if utils.SmartCompare(x, y) == 0 {
+	if !as.eq(x, y) {
+		as.err(AssertionEq, x, y)
+	}
+}
+
+// EqNormalized is similar with Eq, but x and y are compared after both of them are transformed by norm,
+// such as to sort the slices that are unordered sets. The failure still shows the original x and y.
+func (as Assertions) EqNormalized(x, y interface{}, norm func(interface{}) interface{}) {
+	as.Helper()
+	if !as.eq(norm(x), norm(y)) {
+		as.err(AssertionEq, x, y)
+	}
+}
+
+// EqTrimmed asserts that actual equals expected after the trailing whitespaces of each line and the trailing
+// empty lines are removed, such as to compare the generated text that may end with an extra newline.
+// The failure shows the diff of the trimmed strings, so only the differences that matter are reported.
+func (as Assertions) EqTrimmed(actual, expected string) {
+	as.Helper()
+	x, y := trimLines(actual), trimLines(expected)
+	if x != y {
+		as.err(AssertionEq, x, y)
+	}
+}
+
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t\r")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// eq returns true if x equals y by the rules of Eq, the EqualHook is consulted first
+func (as Assertions) eq(x, y interface{}) bool {
+	if handled, equal := as.hookEqual(x, y); handled {
+		return equal
+	}
+	if identical(x, y) {
+		return true
+	}
+	return utils.SmartCompare(x, y) == 0
+}
+
+// EqStrict is similar with Eq, but it keeps the IEEE 754 semantics even when Assertions.EqualHook is EqualNaN,
+// x and y are never equal if any of them holds a NaN, such as a struct with a NaN field.
+func (as Assertions) EqStrict(x, y interface{}) {
+	as.Helper()
+	if utils.HasNaN(x) || utils.HasNaN(y) {
+		as.err(AssertionEq, x, y)
 		return
 	}
-	as.err(AssertionEq, x, y)
+	as.Eq(x, y)
+}
+
+// EqualNaN is an Assertions.EqualHook that treats NaN as equal to NaN, which violates IEEE 754 but is handy for tests,
+// such as to compare the structs with float fields that are NaN. It only handles the values that hold a NaN,
+// they are compared by their dumps where each NaN is rendered the same.
+func EqualNaN(x, y interface{}) (handled, equal bool) {
+	if !utils.HasNaN(x) && !utils.HasNaN(y) {
+		return false, false
+	}
+	return true, utils.Compare(x, y) == 0
+}
+
+// EqReader asserts that the contents of actual and expected are the same, such as the body of an http.Response.
+// Both readers will be read to EOF, so their contents can't be read again after the assertion.
+// If both contents are valid UTF-8, they are reported like Eq with the diff of the text,
+// otherwise the first differing offset is reported with a short hex window around it.
+func (as Assertions) EqReader(actual, expected io.Reader) {
+	as.Helper()
+
+	x, err := ioutil.ReadAll(actual)
+	if err != nil {
+		as.err(AssertionReadErr, "actual", err.Error())
+		return
+	}
+	y, err := ioutil.ReadAll(expected)
+	if err != nil {
+		as.err(AssertionReadErr, "expected", err.Error())
+		return
+	}
+
+	if bytes.Equal(x, y) {
+		return
+	}
+	if utf8.Valid(x) && utf8.Valid(y) {
+		as.err(AssertionEq, string(x), string(y))
+		return
+	}
+	i := firstDiff(x, y)
+	as.err(AssertionEqReader, i, hexWindow(x, i), hexWindow(y, i))
+}
+
+// Diff returns the message that Eq would report for x and y without failing the test, it's empty if they are equal.
+// The message is generated by the ErrorHandler, so the themes of it will be used.
+func (as Assertions) Diff(x, y interface{}) string {
+	if as.eq(x, y) {
+		return ""
+	}
+
+	_, f, l, _ := runtime.Caller(1)
+	return as.ErrorHandler.Report(&AssertionCtx{
+		Type:    AssertionEq,
+		Details: []interface{}{x, y},
+		File:    f,
+		Line:    l,
+	})
 }
 
 // Neq asserts that x not equals y even when converted to the same type.
 func (as Assertions) Neq(x, y interface{}) {
 	as.Helper()
+	if handled, equal := as.hookEqual(x, y); handled {
+		if equal {
+			as.err(AssertionNeqSame, x, y)
+		}
+		return
+	}
 	if utils.SmartCompare(x, y) != 0 {
 		return
 	}
@@ -66,6 +190,12 @@ func (as Assertions) Neq(x, y interface{}) {
 // For loose type comparison use Assertions.Eq, such as compare float 1.0 and integer 1 .
 func (as Assertions) Equal(x, y interface{}) {
 	as.Helper()
+	if handled, equal := as.hookEqual(x, y); handled {
+		if !equal {
+			as.err(AssertionEq, x, y)
+		}
+		return
+	}
 	if utils.Compare(x, y) == 0 {
 		return
 	}
@@ -108,6 +238,24 @@ func (as Assertions) Lte(x, y interface{}) {
 	as.err(AssertionLte, x, y)
 }
 
+// Within asserts that low <= x <= high, the values are compared like Lte.
+func (as Assertions) Within(x, low, high interface{}) {
+	as.Helper()
+	if utils.SmartCompare(x, low) >= 0 && utils.SmartCompare(x, high) <= 0 {
+		return
+	}
+	as.err(AssertionWithin, x, low, high)
+}
+
+// WithinExcl is similar with Within, but it asserts that low < x < high
+func (as Assertions) WithinExcl(x, low, high interface{}) {
+	as.Helper()
+	if utils.SmartCompare(x, low) > 0 && utils.SmartCompare(x, high) < 0 {
+		return
+	}
+	as.err(AssertionWithinExcl, x, low, high)
+}
+
 // InDelta asserts that x and y are within the delta of each other.
 func (as Assertions) InDelta(x, y interface{}, delta float64) {
 	as.Helper()
@@ -126,6 +274,19 @@ func (as Assertions) True(x bool) {
 	as.err(AssertionTrue)
 }
 
+// Assert asserts that cond is true, otherwise it fails with the message formatted from format and args.
+// It's for the ad-hoc conditions that don't fit the other assertions, the terms wrapped with ⦗⦘ in the message
+// will be highlighted like the keywords of the other assertions, such as:
+//
+//     g.Assert(n%2 == 0, "%d ⦗should be even⦘", n)
+func (as Assertions) Assert(cond bool, format string, args ...interface{}) {
+	as.Helper()
+	if cond {
+		return
+	}
+	as.err(AssertionAssert, fmt.Sprintf(format, args...))
+}
+
 // False asserts that x is false.
 func (as Assertions) False(x bool) {
 	as.Helper()
@@ -174,10 +335,11 @@ func (as Assertions) NotNil(args ...interface{}) {
 	}
 }
 
-// Zero asserts x is zero value for its type.
+// Zero asserts x is zero value for its type, such as 0, "", nil map, or a struct with all fields zero.
+// An untyped nil is treated as zero.
 func (as Assertions) Zero(x interface{}) {
 	as.Helper()
-	if reflect.DeepEqual(x, reflect.Zero(reflect.TypeOf(x)).Interface()) {
+	if isZero(x) {
 		return
 	}
 	as.err(AssertionZero, x)
@@ -186,11 +348,15 @@ func (as Assertions) Zero(x interface{}) {
 // NotZero asserts that x is not zero value for its type.
 func (as Assertions) NotZero(x interface{}) {
 	as.Helper()
-	if reflect.DeepEqual(x, reflect.Zero(reflect.TypeOf(x)).Interface()) {
+	if isZero(x) {
 		as.err(AssertionNotZero, x)
 	}
 }
 
+func isZero(x interface{}) bool {
+	return x == nil || reflect.ValueOf(x).IsZero()
+}
+
 // Regex asserts that str matches the regex pattern
 func (as Assertions) Regex(pattern, str string) {
 	as.Helper()
@@ -231,6 +397,150 @@ func (as Assertions) Has(container, item interface{}) {
 	as.err(AssertionHas, container, item)
 }
 
+// Subset asserts that every key in expected exists in actual with an equal value.
+// If both values of a key are maps, they will be compared recursively,
+// so only the keys listed in expected matter.
+func (as Assertions) Subset(actual, expected interface{}) {
+	as.Helper()
+
+	missing, mismatched := subset("", reflect.ValueOf(actual), reflect.ValueOf(expected))
+	if len(missing) == 0 && len(mismatched) == 0 {
+		return
+	}
+	as.err(AssertionSubset, actual, expected, missing, mismatched)
+}
+
+// ElementsMatch asserts that the slices or arrays x and y have the same elements with the same counts, the order doesn't matter.
+// The elements are compared by their dumps like Equal, so the non-comparable types, such as slices and maps, work too.
+func (as Assertions) ElementsMatch(x, y interface{}) {
+	as.Helper()
+	if !as.isKind(x, reflect.Slice, reflect.Array) || !as.isKind(y, reflect.Slice, reflect.Array) {
+		return
+	}
+
+	onlyX, onlyY, counts := elementsMatch(reflect.ValueOf(x), reflect.ValueOf(y))
+	if len(onlyX) == 0 && len(onlyY) == 0 && len(counts) == 0 {
+		return
+	}
+	as.err(AssertionElementsMatch, x, y, onlyX, onlyY, counts)
+}
+
+// HasFunc asserts that at least one item of list satisfies pred, the pred gets the index of the item like sort.Slice,
+// such as to match the slice of structs by one field:
+//
+//     g.HasFunc(users, func(i int) bool { return users[i].Name == "jack" })
+//
+// On failure, only the first few items of list are shown.
+func (as Assertions) HasFunc(list interface{}, pred func(i int) bool) {
+	as.Helper()
+	if !as.isKind(list, reflect.Slice, reflect.Array) {
+		return
+	}
+	v := reflect.ValueOf(list)
+	for i := 0; i < v.Len(); i++ {
+		if pred(i) {
+			return
+		}
+	}
+	as.err(AssertionHasFunc, headItems(v), v.Len())
+}
+
+// AllFunc asserts that every item of list satisfies pred, the pred gets the index of the item like sort.Slice.
+// It reports the first item that doesn't satisfy pred.
+func (as Assertions) AllFunc(list interface{}, pred func(i int) bool) {
+	as.Helper()
+	if !as.isKind(list, reflect.Slice, reflect.Array) {
+		return
+	}
+	v := reflect.ValueOf(list)
+	for i := 0; i < v.Len(); i++ {
+		if !pred(i) {
+			as.err(AssertionAllFunc, i, v.Index(i).Interface())
+			return
+		}
+	}
+}
+
+// FileExists asserts that path exists and is not a directory
+func (as Assertions) FileExists(path string) {
+	as.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		as.err(AssertionFileExists, path, err.Error())
+	} else if info.IsDir() {
+		as.err(AssertionFileExists, path, "is a directory")
+	}
+}
+
+// DirExists asserts that path exists and is a directory
+func (as Assertions) DirExists(path string) {
+	as.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		as.err(AssertionDirExists, path, err.Error())
+	} else if !info.IsDir() {
+		as.err(AssertionDirExists, path, "is not a directory")
+	}
+}
+
+// FileContains asserts that the content of the file at path contains substr
+func (as Assertions) FileContains(path, substr string) {
+	as.Helper()
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		as.err(AssertionFileExists, path, err.Error())
+		return
+	}
+	if err != nil {
+		as.err(AssertionFileRead, path, err.Error())
+		return
+	}
+	if strings.Contains(string(b), substr) {
+		return
+	}
+	as.err(AssertionFileContains, path, substr, string(b))
+}
+
+// Sorted asserts that the items of list are sorted by less, the less is the same as the one of sort.Slice.
+// It reports the first pair of the adjacent items that are out of order.
+func (as Assertions) Sorted(list interface{}, less func(i, j int) bool) {
+	as.Helper()
+	if !as.isKind(list, reflect.Slice, reflect.Array) {
+		return
+	}
+	v := reflect.ValueOf(list)
+	for i := 1; i < v.Len(); i++ {
+		if less(i, i-1) {
+			as.err(AssertionSorted, i-1, i, v.Index(i-1).Interface(), v.Index(i).Interface())
+			return
+		}
+	}
+}
+
+// SortedAsc asserts that the items of list are in ascending order, the items are compared like Lte
+func (as Assertions) SortedAsc(list interface{}) {
+	as.Helper()
+	if !as.isKind(list, reflect.Slice, reflect.Array) {
+		return
+	}
+	v := reflect.ValueOf(list)
+	as.Sorted(list, func(i, j int) bool {
+		return utils.SmartCompare(v.Index(i).Interface(), v.Index(j).Interface()) < 0
+	})
+}
+
+// SortedDesc asserts that the items of list are in descending order, the items are compared like Gte
+func (as Assertions) SortedDesc(list interface{}) {
+	as.Helper()
+	if !as.isKind(list, reflect.Slice, reflect.Array) {
+		return
+	}
+	v := reflect.ValueOf(list)
+	as.Sorted(list, func(i, j int) bool {
+		return utils.SmartCompare(v.Index(i).Interface(), v.Index(j).Interface()) > 0
+	})
+}
+
 // Len asserts that the length of list equals l
 func (as Assertions) Len(list interface{}, l int) {
 	as.Helper()
@@ -255,6 +565,133 @@ func (as Assertions) Err(args ...interface{}) {
 	as.err(AssertionErr, last, args)
 }
 
+// EqErr asserts that the message of err contains substr
+func (as Assertions) EqErr(err error, substr string) {
+	as.Helper()
+	if err == nil {
+		as.err(AssertionErr, err)
+		return
+	}
+	if strings.Contains(err.Error(), substr) {
+		return
+	}
+	as.err(AssertionEqErr, err.Error(), substr, errChain(err))
+}
+
+// ErrorContains is similar with EqErr, but the substr is formatted from format and args via fmt.Sprintf
+func (as Assertions) ErrorContains(err error, format string, args ...interface{}) {
+	as.Helper()
+	as.EqErr(err, fmt.Sprintf(format, args...))
+}
+
+// EqErrExact asserts that the message of x equals the message of y
+func (as Assertions) EqErrExact(x, y error) {
+	as.Helper()
+	if x == nil && y == nil {
+		return
+	}
+	if x != nil && y != nil && x.Error() == y.Error() {
+		return
+	}
+	as.err(AssertionEqErrExact, errMsg(x), errMsg(y), errChain(x))
+}
+
+// AllOf asserts that all the conds pass. Each cond is called with a G that records its own failures,
+// they will be reported together with the index of each failed cond.
+func (as Assertions) AllOf(conds ...func(g G)) {
+	as.Helper()
+	failures := as.checkConds(conds)
+	if len(failures) == 0 {
+		return
+	}
+	as.err(AssertionAllOf, failures)
+}
+
+// AnyOf asserts that at least one of the conds passes. Each cond is called with a G that records its own failures,
+// if all of them fail, they will be reported together with the index of each cond.
+func (as Assertions) AnyOf(conds ...func(g G)) {
+	as.Helper()
+	failures := as.checkConds(conds)
+	if len(failures) < len(conds) {
+		return
+	}
+	as.err(AssertionAnyOf, failures)
+}
+
+// Eventually asserts that fn passes within the timeout, fn will be retried every interval until it passes.
+// Each attempt is called with a G that records its own failures, the failures of the last attempt will be reported.
+func (as Assertions) Eventually(timeout, interval time.Duration, fn func(g G)) {
+	as.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	as.eventually(ctx, interval, fn)
+}
+
+// EventuallyCtx is similar with Eventually, but it stops retrying when the ctx is done,
+// so it won't outlive the ctx, such as the one returned by Utils.Context .
+func (as Assertions) EventuallyCtx(ctx context.Context, interval time.Duration, fn func(g G)) {
+	as.Helper()
+	as.eventually(ctx, interval, fn)
+}
+
+func (as Assertions) eventually(ctx context.Context, interval time.Duration, fn func(g G)) {
+	as.Helper()
+
+	attempts := 0
+	last := ""
+	for ctx.Err() == nil {
+		attempts++
+		failures := as.checkConds([]func(g G){fn})
+		if len(failures) == 0 {
+			return
+		}
+		last = failures[0].msg
+
+		tmr := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+		case <-tmr.C:
+		}
+		tmr.Stop()
+	}
+	as.err(AssertionEventually, attempts, last, ctx.Err())
+}
+
+// Recv asserts that a value can be received from the channel ch within the timeout, it returns the received value.
+func (as Assertions) Recv(ch interface{}, timeout time.Duration) interface{} {
+	as.Helper()
+	if !as.isKind(ch, reflect.Chan) {
+		return nil
+	}
+	v, ok, received := recv(ch, timeout)
+	if !received {
+		as.err(AssertionRecvTimeout, timeout)
+		return nil
+	}
+	if !ok {
+		as.err(AssertionRecvClosed)
+		return nil
+	}
+	return v
+}
+
+// NoRecv asserts that nothing can be received from the channel ch within the dur.
+func (as Assertions) NoRecv(ch interface{}, dur time.Duration) {
+	as.Helper()
+	if !as.isKind(ch, reflect.Chan) {
+		return
+	}
+	v, ok, received := recv(ch, dur)
+	if !received {
+		return
+	}
+	if !ok {
+		as.err(AssertionRecvClosed)
+		return
+	}
+	as.err(AssertionNoRecv, v, dur)
+}
+
 // E is a shortcut for Must().Nil(args...)
 func (as Assertions) E(args ...interface{}) {
 	as.Helper()
@@ -352,6 +789,92 @@ func (as Assertions) err(t AssertionErrType, details ...interface{}) {
 	as.Fail()
 }
 
+// isKind returns true if the kind of x is one of the kinds, otherwise it reports the failure
+func (as Assertions) isKind(x interface{}, kinds ...reflect.Kind) bool {
+	as.Helper()
+	names := []string{}
+	for _, kind := range kinds {
+		if reflect.ValueOf(x).Kind() == kind {
+			return true
+		}
+		names = append(names, kind.String())
+	}
+	as.err(AssertionWrongKind, x, strings.Join(names, " or "))
+	return false
+}
+
+// recv returns true for received if the ch isn't blocked in the timeout, ok is false if the ch is closed
+func recv(ch interface{}, timeout time.Duration) (v interface{}, ok, received bool) {
+	chosen, val, ok := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(time.After(timeout))},
+	})
+	if chosen == 1 {
+		return nil, false, false
+	}
+	if !ok {
+		return nil, false, true
+	}
+	return val.Interface(), true, true
+}
+
+type condFailure struct {
+	index int
+	msg   string
+}
+
+// checkConds runs each cond with a Mock, it returns the failures of the conds that don't pass
+func (as Assertions) checkConds(conds []func(g G)) []condFailure {
+	failures := []condFailure{}
+	for i, cond := range conds {
+		cond := cond
+		m := MockTestable(fmt.Sprintf("%s#%d", as.Name(), i))
+		if !m.Check(func(g G) {
+			g.ErrorHandler = as.ErrorHandler
+			cond(g)
+		}) {
+			failures = append(failures, condFailure{i, strings.Join(m.Failures(), "\n")})
+		}
+	}
+	return failures
+}
+
+func (as Assertions) hookEqual(x, y interface{}) (handled, equal bool) {
+	if as.EqualHook == nil {
+		return false, false
+	}
+	return as.EqualHook(x, y)
+}
+
+// errChain returns the messages of each error in the chain of err
+func errChain(err error) []string {
+	list := []string{}
+	for ; err != nil; err = errors.Unwrap(err) {
+		list = append(list, err.Error())
+	}
+	return list
+}
+
+func errMsg(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+	return err.Error()
+}
+
+// identical returns true if x == y, it's the fast path of Eq to skip the deep comparison and the dumps,
+// such as to assert a cached value equals itself.
+func identical(x, y interface{}) (same bool) {
+	if x == nil || !reflect.TypeOf(x).Comparable() {
+		return false
+	}
+
+	// the interface fields of a comparable struct may hold uncomparable values
+	defer func() { _ = recover() }()
+
+	return x == y
+}
+
 // the first return value is true if x is nilable
 func isNil(x interface{}) (bool, bool) {
 	if x == nil {
@@ -374,6 +897,120 @@ func isNil(x interface{}) (bool, bool) {
 	return false, false
 }
 
+// headItemsLimit is the max number of items of a list to show in the failure of HasFunc
+const headItemsLimit = 10
+
+// headItems returns a slice of the first headItemsLimit items of the slice or array v
+func headItems(v reflect.Value) interface{} {
+	n := v.Len()
+	if n > headItemsLimit {
+		n = headItemsLimit
+	}
+	head := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), 0, n)
+	for i := 0; i < n; i++ {
+		head = reflect.Append(head, v.Index(i))
+	}
+	return head.Interface()
+}
+
+// elementCount is the count of an element in each side of ElementsMatch
+type elementCount struct {
+	element interface{}
+	x, y    int
+}
+
+// elementsMatch returns the elements that only x or y has, and the elements whose counts differ,
+// they are in the order of the first appearance in x then y.
+func elementsMatch(x, y reflect.Value) (onlyX, onlyY []interface{}, counts []elementCount) {
+	tz := gop.NewTokenizer()
+	tz.NoTruncate = true
+
+	keys := []string{}
+	list := map[string]*elementCount{}
+	add := func(v reflect.Value, isX bool) {
+		for i := 0; i < v.Len(); i++ {
+			el := v.Index(i).Interface()
+			key := gop.Format(tz.Tokenize(el), gop.ThemeNone)
+			c, has := list[key]
+			if !has {
+				c = &elementCount{element: el}
+				list[key] = c
+				keys = append(keys, key)
+			}
+			if isX {
+				c.x++
+			} else {
+				c.y++
+			}
+		}
+	}
+	add(x, true)
+	add(y, false)
+
+	for _, key := range keys {
+		c := list[key]
+		switch {
+		case c.y == 0:
+			onlyX = append(onlyX, c.element)
+		case c.x == 0:
+			onlyY = append(onlyY, c.element)
+		case c.x != c.y:
+			counts = append(counts, *c)
+		}
+	}
+	return
+}
+
+// subset returns the paths of the keys that are missing in x, and the paths of the values that don't equal.
+// The keys of y that can't be the keys of x, such as an int key for a map[string]int, are treated as mismatched.
+func subset(p string, x, y reflect.Value) (missing, mismatched []string) {
+	for x.Kind() == reflect.Interface {
+		x = x.Elem()
+	}
+	for y.Kind() == reflect.Interface {
+		y = y.Elem()
+	}
+
+	if x.Kind() != reflect.Map || y.Kind() != reflect.Map {
+		if utils.SmartCompare(toInterface(x), toInterface(y)) != 0 {
+			mismatched = append(mismatched, p)
+		}
+		return
+	}
+
+	keys := y.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return utils.Compare(keys[i].Interface(), keys[j].Interface()) < 0
+	})
+
+	for _, k := range keys {
+		kp := p + "[" + gop.Plain(k.Interface()) + "]"
+
+		if !k.Type().AssignableTo(x.Type().Key()) {
+			mismatched = append(mismatched, kp)
+			continue
+		}
+
+		xv := x.MapIndex(k)
+		if !xv.IsValid() {
+			missing = append(missing, kp)
+			continue
+		}
+
+		m, mm := subset(kp, xv, y.MapIndex(k))
+		missing = append(missing, m...)
+		mismatched = append(mismatched, mm...)
+	}
+	return
+}
+
+func toInterface(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
 func hasStr(c string, item interface{}) bool {
 	if it, ok := item.(string); ok {
 		if strings.Contains(c, it) {
@@ -1,12 +1,20 @@
 package got
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/ysmood/got/lib/diff"
 	"github.com/ysmood/got/lib/gop"
+	"github.com/ysmood/got/lib/utils"
 )
 
 // AssertionErrType enum
@@ -63,6 +71,60 @@ const (
 	AssertionIsKind
 	// AssertionCount type
 	AssertionCount
+	// AssertionSubset type
+	AssertionSubset
+	// AssertionEqErr type
+	AssertionEqErr
+	// AssertionEqErrExact type
+	AssertionEqErrExact
+	// AssertionAllOf type
+	AssertionAllOf
+	// AssertionAnyOf type
+	AssertionAnyOf
+	// AssertionRecvTimeout type
+	AssertionRecvTimeout
+	// AssertionRecvClosed type
+	AssertionRecvClosed
+	// AssertionNoRecv type
+	AssertionNoRecv
+	// AssertionSnapshot type
+	AssertionSnapshot
+	// AssertionEventually type
+	AssertionEventually
+	// AssertionGolden type
+	AssertionGolden
+	// AssertionSnapshotBytes type
+	AssertionSnapshotBytes
+	// AssertionFileExists type
+	AssertionFileExists
+	// AssertionDirExists type
+	AssertionDirExists
+	// AssertionFileContains type
+	AssertionFileContains
+	// AssertionSorted type
+	AssertionSorted
+	// AssertionWithin type
+	AssertionWithin
+	// AssertionWithinExcl type
+	AssertionWithinExcl
+	// AssertionAssert type
+	AssertionAssert
+	// AssertionElementsMatch type
+	AssertionElementsMatch
+	// AssertionReadErr type
+	AssertionReadErr
+	// AssertionEqReader type
+	AssertionEqReader
+	// AssertionHasFunc type
+	AssertionHasFunc
+	// AssertionAllFunc type
+	AssertionAllFunc
+	// AssertionSnapshotMissing type
+	AssertionSnapshotMissing
+	// AssertionWrongKind type
+	AssertionWrongKind
+	// AssertionFileRead type
+	AssertionFileRead
 )
 
 // AssertionCtx holds the context of an assertion
@@ -83,6 +145,8 @@ var _ AssertionError = AssertionErrorReport(nil)
 // AssertionErrorReport is used to convert a func to AssertionError
 type AssertionErrorReport func(*AssertionCtx) string
 
+var regKeyword = regexp.MustCompile(`⦗[^⦘]*⦘`)
+
 // Report interface
 func (ae AssertionErrorReport) Report(ac *AssertionCtx) string {
 	return ae(ac)
@@ -110,10 +174,37 @@ func NewDefaultAssertionError(theme gop.Theme, diffTheme diff.Theme) AssertionEr
 			x := f(details[0])
 			y := f(details[1])
 
+			if reflect.TypeOf(details[0]) != reflect.TypeOf(details[1]) {
+				// such as 5 and int64(5), the types of both sides should be obvious
+				x, y = typedScalar(details[0], x, theme), typedScalar(details[1], y, theme)
+			}
+
+			if bx, ok := details[0].([]byte); ok {
+				if by, ok := details[1].([]byte); ok && !(utf8.Valid(bx) && utf8.Valid(by)) {
+					// the base64 of binary data can't be diffed meaningfully
+					x, y = quoteLines(bx), quoteLines(by)
+				}
+			}
+
 			if diffTheme == nil {
 				return j(x, k("not =="), y)
 			}
 
+			// when no key differs, such as only the types of the values differ, the line diff below explains it
+			if changed, onlyX, onlyY, ok := mapDiff(details[0], details[1]); ok && len(changed)+len(onlyX)+len(onlyY) > 0 {
+				list := []string{x, k("not =="), y}
+				if len(changed) > 0 {
+					list = append(list, k("different values of keys"), f(changed))
+				}
+				if len(onlyX) > 0 {
+					list = append(list, k("keys only in the first"), f(onlyX))
+				}
+				if len(onlyY) > 0 {
+					list = append(list, k("keys only in the second"), f(onlyY))
+				}
+				return j(list...)
+			}
+
 			if hasNewline(x, y) {
 				df := diff.Format(diff.Tokenize(ctx, x, y), diffTheme)
 				return j(x, k("not =="), y, df)
@@ -227,6 +318,176 @@ func NewDefaultAssertionError(theme gop.Theme, diffTheme diff.Theme) AssertionEr
 			count := f(details[1])
 			return k("should count") + n + k("times, but got") + count
 		},
+		AssertionSubset: func(details ...interface{}) string {
+			actual := f(details[0])
+			expected := f(details[1])
+			list := []string{actual, k("should contain subset"), expected}
+			if missing := details[2].([]string); len(missing) > 0 {
+				list = append(list, k("missing keys"), f(missing))
+			}
+			if mismatched := details[3].([]string); len(mismatched) > 0 {
+				list = append(list, k("mismatched keys"), f(mismatched))
+			}
+			return j(list...)
+		},
+		AssertionElementsMatch: func(details ...interface{}) string {
+			list := []string{f(details[0]), k("should match the elements of"), f(details[1])}
+			if onlyX := details[2].([]interface{}); len(onlyX) > 0 {
+				list = append(list, k("only in the first"), f(onlyX))
+			}
+			if onlyY := details[3].([]interface{}); len(onlyY) > 0 {
+				list = append(list, k("only in the second"), f(onlyY))
+			}
+			if counts := details[4].([]elementCount); len(counts) > 0 {
+				list = append(list, k("different counts"))
+				for _, c := range counts {
+					list = append(list, f(c.element)+k("appears")+f(c.x)+k("vs")+f(c.y))
+				}
+			}
+			return j(list...)
+		},
+		AssertionHasFunc: func(details ...interface{}) string {
+			head, total := reflect.ValueOf(details[0]), details[1].(int)
+			if head.Len() < total {
+				return j(k("no item satisfies the predicate, the first")+f(head.Len())+k("of")+f(total)+k("items are"), f(head.Interface()))
+			}
+			return j(k("no item satisfies the predicate in"), f(head.Interface()))
+		},
+		AssertionAllFunc: func(details ...interface{}) string {
+			return j(k("item at index")+f(details[0])+k("doesn't satisfy the predicate"), f(details[1]))
+		},
+		AssertionSnapshotMissing: func(details ...interface{}) string {
+			return k("snapshot") + details[0].(string) + k("doesn't exist, it won't be created when the env var is") +
+				"UPDATE_SNAPSHOTS=never"
+		},
+		AssertionWrongKind: func(details ...interface{}) string {
+			return f(details[0]) + k("should be the kind of") + details[1].(string)
+		},
+		AssertionEqErr: func(details ...interface{}) string {
+			msg := f(details[0])
+			substr := f(details[1])
+			chain := f(details[2])
+			return j(msg, k("should contain"), substr, k("error chain"), chain)
+		},
+		AssertionEqErrExact: func(details ...interface{}) string {
+			x := f(details[0])
+			y := f(details[1])
+			chain := f(details[2])
+			return j(x, k("not =="), y, k("error chain"), chain)
+		},
+		AssertionAllOf: func(details ...interface{}) string {
+			return condFailures(k, k("all of the conditions should pass"), details[0])
+		},
+		AssertionAnyOf: func(details ...interface{}) string {
+			return condFailures(k, k("any of the conditions should pass"), details[0])
+		},
+		AssertionEventually: func(details ...interface{}) string {
+			attempts := f(details[0])
+			last := details[1].(string)
+			reason := "context cancelled after"
+			if errors.Is(details[2].(error), context.DeadlineExceeded) {
+				reason = "timed out after"
+			}
+			if last == "" {
+				return k(reason) + attempts + k("attempts")
+			}
+			return k(reason) + attempts + k("attempts, the last failure") + last
+		},
+		AssertionGolden: func(details ...interface{}) string {
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			title := k("golden file") + details[0].(string) + k("mismatch")
+			x, y := details[1].(string), details[2].(string)
+
+			if diffTheme == nil {
+				return j(title, f(x), k("not =="), f(y))
+			}
+			return j(title, diff.Format(diff.Tokenize(ctx, x, y), diffTheme))
+		},
+		AssertionSnapshotBytes: func(details ...interface{}) string {
+			offset := f(details[1])
+			return j(k("snapshot")+details[0].(string)+k("mismatch at offset")+offset,
+				k("actual")+details[2].(string), k("expected")+details[3].(string))
+		},
+		AssertionReadErr: func(details ...interface{}) string {
+			return k("failed to read the") + details[0].(string) + k("reader") + details[1].(string)
+		},
+		AssertionEqReader: func(details ...interface{}) string {
+			offset := f(details[0])
+			return j(k("contents mismatch at offset")+offset,
+				k("actual")+details[1].(string), k("expected")+details[2].(string))
+		},
+		AssertionFileExists: func(details ...interface{}) string {
+			return k("file") + details[0].(string) + k("should exist, but") + details[1].(string)
+		},
+		AssertionFileRead: func(details ...interface{}) string {
+			return k("file") + details[0].(string) + k("can't read") + details[1].(string)
+		},
+		AssertionDirExists: func(details ...interface{}) string {
+			return k("directory") + details[0].(string) + k("should exist, but") + details[1].(string)
+		},
+		AssertionFileContains: func(details ...interface{}) string {
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			substr, content := details[1].(string), details[2].(string)
+			title := k("file") + details[0].(string) + k("should contain") + f(substr)
+
+			if diffTheme == nil {
+				return j(title, k("content"), f(content))
+			}
+			return j(title, k("content"), f(content), diff.Format(diff.Tokenize(ctx, content, substr), diffTheme))
+		},
+		AssertionSorted: func(details ...interface{}) string {
+			i, next := f(details[0]), f(details[1])
+			x, y := f(details[2]), f(details[3])
+			return j(x, k("at index")+i+k("should not be before"), y, k("at index")+next)
+		},
+		AssertionWithin: func(details ...interface{}) string {
+			x, low, high := f(details[0]), f(details[1]), f(details[2])
+			return j(x, k("should be within"), low, k("and"), high)
+		},
+		AssertionWithinExcl: func(details ...interface{}) string {
+			x, low, high := f(details[0]), f(details[1]), f(details[2])
+			return j(x, k("should be strictly between"), low, k("and"), high)
+		},
+		AssertionAssert: func(details ...interface{}) string {
+			return regKeyword.ReplaceAllStringFunc(details[0].(string), func(s string) string {
+				return gop.Stylize(s, theme(gop.Error))
+			})
+		},
+		AssertionRecvTimeout: func(details ...interface{}) string {
+			timeout := f(details[0])
+			return k("nothing received from the channel within") + timeout
+		},
+		AssertionRecvClosed: func(_ ...interface{}) string {
+			return k("channel closed")
+		},
+		AssertionNoRecv: func(details ...interface{}) string {
+			v := f(details[0])
+			dur := f(details[1])
+			return j(k("should receive nothing from the channel within"), dur, k("but received"), v)
+		},
+	}
+
+	fns[AssertionSnapshot] = func(details ...interface{}) string {
+		title := k("snapshot") + details[0].(string) + k("mismatch, set the env var to update it") + "UPDATE_SNAPSHOTS=true"
+		if diffTheme == nil {
+			return title + "\n" + fns[AssertionEq](details[1:]...)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		// only show the changed regions, the snapshot can be large
+		text := func(v interface{}) string {
+			if s, ok := v.(string); ok {
+				return s
+			}
+			return f(v)
+		}
+		return j(title, diff.Format(diff.Tokenize(ctx, text(details[1]), text(details[2])), diffTheme))
 	}
 
 	return &defaultAssertionError{fns: fns}
@@ -237,6 +498,15 @@ func (ae *defaultAssertionError) Report(ac *AssertionCtx) string {
 	return ae.fns[ac.Type](ac.Details...)
 }
 
+// condFailures lists each failure in a new line, because there are usually multiple of them
+func condFailures(k func(string) string, title string, failures interface{}) string {
+	list := []string{title}
+	for _, f := range failures.([]condFailure) {
+		list = append(list, k("condition "+strconv.Itoa(f.index)+" failed")+f.msg)
+	}
+	return "\n" + strings.Join(list, "\n\n")
+}
+
 func j(args ...string) string {
 	if hasNewline(args...) {
 		return "\n" + strings.Join(args, "\n\n")
@@ -252,3 +522,77 @@ func hasNewline(args ...string) bool {
 	}
 	return false
 }
+
+// mapDiff returns the keys whose values differ, and the keys that only x or y has, ok is false if x or y isn't a map.
+// The keys are matched by utils.SmartCompare, so the maps of different types can be compared like Eq,
+// such as map[int]int and map[int64]int, they are in sorted order.
+func mapDiff(x, y interface{}) (changed, onlyX, onlyY []interface{}, ok bool) {
+	xv, yv := reflect.ValueOf(x), reflect.ValueOf(y)
+	if xv.Kind() != reflect.Map || yv.Kind() != reflect.Map {
+		return nil, nil, nil, false
+	}
+
+	yKeys := yv.MapKeys()
+	matched := make([]bool, len(yKeys))
+	match := func(xk reflect.Value) (reflect.Value, bool) {
+		for i, yk := range yKeys {
+			if !matched[i] && utils.SmartCompare(xk.Interface(), yk.Interface()) == 0 {
+				matched[i] = true
+				return yk, true
+			}
+		}
+		return reflect.Value{}, false
+	}
+
+	for _, xk := range xv.MapKeys() {
+		yk, has := match(xk)
+		if !has {
+			onlyX = append(onlyX, xk.Interface())
+		} else if utils.SmartCompare(xv.MapIndex(xk).Interface(), yv.MapIndex(yk).Interface()) != 0 {
+			changed = append(changed, xk.Interface())
+		}
+	}
+	for i, yk := range yKeys {
+		if !matched[i] {
+			onlyY = append(onlyY, yk.Interface())
+		}
+	}
+
+	sorted := func(list []interface{}) []interface{} {
+		sort.Slice(list, func(i, j int) bool { return utils.SmartCompare(list[i], list[j]) < 0 })
+		// the cap will be shown in the dump, it shouldn't depend on the growth of append
+		return list[:len(list):len(list)]
+	}
+	return sorted(changed), sorted(onlyX), sorted(onlyY), true
+}
+
+// typedScalar wraps the dump of the scalar v with the conversion to its type, such as int(5),
+// if the dump is a bare literal that doesn't show the type
+func typedScalar(v interface{}, dump string, theme gop.Theme) string {
+	if v == nil {
+		return dump
+	}
+
+	// the comments of the options, such as gop.ShowKind, stay before the conversion
+	ts := gop.Tokenize(v)
+	i := 0
+	for i < len(ts)-1 && ts[i].Type == gop.Comment {
+		i++
+	}
+
+	switch ts[i].Type {
+	case gop.Number, gop.String, gop.Bool:
+		return gop.Format(ts[:i], theme) + gop.Stylize(reflect.TypeOf(v).String(), theme(gop.TypeName)) +
+			"(" + gop.Format(ts[i:], theme) + ")"
+	}
+	return dump
+}
+
+// quoteLines quotes each line of b, so that the non-printable bytes are escaped and the diff is still line based
+func quoteLines(b []byte) string {
+	lines := []string{}
+	for _, l := range bytes.Split(b, []byte("\n")) {
+		lines = append(lines, strconv.Quote(string(l)))
+	}
+	return strings.Join(lines, "\n")
+}
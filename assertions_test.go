@@ -1,15 +1,22 @@
 package got_test
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/ysmood/got"
+	"github.com/ysmood/got/lib/diff"
 	"github.com/ysmood/got/lib/gop"
+	"github.com/ysmood/got/lib/utils"
 )
 
 func TestAssertion(t *testing.T) {
@@ -17,6 +24,8 @@ func TestAssertion(t *testing.T) {
 
 	as.Eq(1, 1)
 	as.Eq(1.0, 1)
+	as.Eq(int32(5), int64(5))
+	as.Eq(uint8(5), 5.0)
 	as.Eq([]int{1, 3}, []int{1, 3})
 	as.Eq(map[int]int{1: 2, 3: 4}, map[int]int{3: 4, 1: 2})
 	as.Eq(nil, nil)
@@ -37,11 +46,23 @@ func TestAssertion(t *testing.T) {
 	as.Lte(1, 1)
 
 	as.Gt(2, 1.5)
+	as.Within(1, 1, 2)
+	as.Within(2, 1, 2)
+	as.Within(time.Millisecond, 0, time.Second)
+	as.WithinExcl(1.5, 1, 2)
+	as.Assert(true, "unreachable")
+	as.EqReader(strings.NewReader("abc"), bytes.NewBufferString("abc"))
+	users := []struct{ Name string }{{"a"}, {"jack"}}
+	as.HasFunc(users, func(i int) bool { return users[i].Name == "jack" })
+	as.AllFunc([2]int{1, 2}, func(i int) bool { return i < 2 })
+	as.ElementsMatch([]int{1, 2, 2, 3}, [4]int{2, 3, 2, 1})
+	as.ElementsMatch([][]int{{1}, nil}, []interface{}{[]int(nil), []int{1}})
 	as.Gte(2, 2.0)
 
 	now := time.Now()
 	as.Eq(now, now)
 	as.Lt(now, now.Add(time.Second))
+	as.Eq(now, now.In(time.FixedZone("X", 3600)).Round(0))
 	as.Gt(now.Add(time.Second), now)
 
 	as.InDelta(1.1, 1.2, 0.2)
@@ -57,9 +78,13 @@ func TestAssertion(t *testing.T) {
 	as.Zero("")
 	as.Zero(0)
 	as.Zero(time.Time{})
+	as.Zero(nil)
+	as.Zero(map[int]int(nil))
+	as.Zero(struct{ A []int }{})
 	as.NotZero(1)
 	as.NotZero("ok")
 	as.NotZero(time.Now())
+	as.NotZero([]int{})
 
 	as.Regex(`\d\d`, "10")
 	as.Has(`test`, 'e')
@@ -71,9 +96,42 @@ func TestAssertion(t *testing.T) {
 	as.Has([3]int{1, 2, 3}, 2)
 	as.Has(map[int]int{1: 4, 2: 5, 3: 6}, 5)
 
+	as.Subset(map[string]int{"a": 1, "b": 2, "c": 3}, map[string]int{"a": 1, "b": 2})
+	as.Subset(
+		map[string]interface{}{"a": map[string]interface{}{"b": 1, "c": 2}, "d": 3},
+		map[string]interface{}{"a": map[string]interface{}{"b": 1.0}},
+	)
+	as.Subset(nil, nil)
+
 	as.Len([]int{1, 2}, 2)
+	words := []string{"ccc", "bb", "a"}
+	as.Sorted(words, func(i, j int) bool { return len(words[i]) > len(words[j]) })
+	as.SortedAsc([]int{})
+	as.SortedAsc([]int{1})
+	as.SortedAsc([]int{1, 2, 2, 3})
+	as.SortedDesc([]float64{3, 2.5, 1})
+
+	as.FileExists("go.mod")
+	as.DirExists("lib")
+	as.FileContains("go.mod", "module github.com/ysmood/got")
 
 	as.Err(1, 2, errors.New("err"))
+	as.EqErr(fmt.Errorf("open: %w", errors.New("not found")), "not found")
+	as.EqErrExact(fmt.Errorf("%w", errors.New("err")), errors.New("err"))
+	as.EqErrExact(nil, nil)
+	as.ErrorContains(fmt.Errorf("open %s: %w", "a.txt", errors.New("not found")), "open %s", "a.txt")
+	as.AllOf(func(g got.G) { g.Eq(1, 1) }, func(g got.G) { g.Lt(1, 2) })
+	as.AnyOf(func(g got.G) { g.Eq(1, 2) }, func(g got.G) { g.Eq(1, 1) })
+	attempts := 0
+	as.Eventually(time.Second, time.Millisecond, func(g got.G) {
+		attempts++
+		g.Gt(attempts, 2)
+	})
+	as.EventuallyCtx(as.Context(), time.Millisecond, func(g got.G) {})
+	ch := make(chan int, 1)
+	ch <- 1
+	as.Eq(as.Recv(ch, time.Second), 1)
+	as.NoRecv(ch, time.Millisecond)
 	as.Panic(func() { panic(1) })
 
 	as.Is(1, 2)
@@ -100,7 +158,7 @@ func TestAssertionErr(t *testing.T) {
 	}
 
 	as.Desc("not %s", "equal").Eq(1, 2.0)
-	m.check("not equal\n1 ⦗not ==⦘ float64(2)")
+	m.check("not equal\nint(1) ⦗not ==⦘ float64(2)")
 
 	as.Eq(data{1, "a"}, data{1, "b"})
 	m.check(`
@@ -117,16 +175,19 @@ got_test.data/* len=2 */{
 }`)
 
 	as.Eq(true, "a&")
-	m.check(`true ⦗not ==⦘ "a&"`)
+	m.check(`bool(true) ⦗not ==⦘ string("a&")`)
 
 	as.Eq(nil, "ok")
-	m.check(`nil ⦗not ==⦘ "ok"`)
+	m.check(`nil ⦗not ==⦘ string("ok")`)
 
 	as.Eq(1, nil)
-	m.check(`1 ⦗not ==⦘ nil`)
+	m.check(`int(1) ⦗not ==⦘ nil`)
+
+	as.Eq(int32(5), "5")
+	m.check(`int32(5) ⦗not ==⦘ string("5")`)
 
 	as.Equal(1, 1.0)
-	m.check("1 ⦗not ==⦘ float64(1)")
+	m.check("int(1) ⦗not ==⦘ float64(1)")
 	as.Equal([]int{1}, []int{2})
 	m.check(`
 []int/* len=1 cap=1 */{
@@ -179,15 +240,181 @@ got_test.data/* len=2 */{
 	m.check("1 ⦗should be zero value for its type⦘ ")
 	as.NotZero(0)
 	m.check("0 ⦗shouldn't be zero value for its type⦘ ")
+	as.NotZero(nil)
+	m.check("nil ⦗shouldn't be zero value for its type⦘ ")
 
 	as.Regex(`\d\d`, "aaa")
 	m.check(`"\\d\\d" ⦗should match⦘ "aaa"`)
 	as.Has(`test`, "x")
 	m.check(`"test" ⦗should has⦘ "x"`)
 
+	as.Subset(map[string]int{"a": 1}, map[string]int{"b": 1})
+	m.check("" +
+		"\n" +
+		"map[string]int{\n" +
+		"    \"a\": 1,\n" +
+		"}\n\n" +
+		" ⦗should contain subset⦘ \n\n" +
+		"map[string]int{\n" +
+		"    \"b\": 1,\n" +
+		"}\n\n" +
+		" ⦗missing keys⦘ \n\n" +
+		"[]string/* len=1 cap=1 */{\n" +
+		"    `[\"b\"]`,\n" +
+		"}")
+	as.Subset(
+		map[string]interface{}{"a": map[string]int{"b": 1}},
+		map[string]interface{}{"a": map[string]int{"b": 2}},
+	)
+	m.check("" +
+		"\n" +
+		"gop.Obj{\n" +
+		"    \"a\": map[string]int{\n" +
+		"        \"b\": 1,\n" +
+		"    },\n" +
+		"}\n\n" +
+		" ⦗should contain subset⦘ \n\n" +
+		"gop.Obj{\n" +
+		"    \"a\": map[string]int{\n" +
+		"        \"b\": 2,\n" +
+		"    },\n" +
+		"}\n\n" +
+		" ⦗mismatched keys⦘ \n\n" +
+		"[]string/* len=1 cap=1 */{\n" +
+		"    `[\"a\"][\"b\"]`,\n" +
+		"}")
+	as.Subset(
+		map[string]interface{}{"a": map[string]int{"b": 1}},
+		map[string]interface{}{"a": map[int]int{1: 1}},
+	)
+	m.check("" +
+		"\n" +
+		"gop.Obj{\n" +
+		"    \"a\": map[string]int{\n" +
+		"        \"b\": 1,\n" +
+		"    },\n" +
+		"}\n\n" +
+		" ⦗should contain subset⦘ \n\n" +
+		"gop.Obj{\n" +
+		"    \"a\": map[int]int{\n" +
+		"        1: 1,\n" +
+		"    },\n" +
+		"}\n\n" +
+		" ⦗mismatched keys⦘ \n\n" +
+		"[]string/* len=1 cap=1 */{\n" +
+		"    `[\"a\"][1]`,\n" +
+		"}")
+
 	as.Len([]int{1, 2}, 3)
 	m.check(" ⦗expect len⦘ 2 ⦗to be⦘ 3")
 
+	as.Within(3, 1, 2)
+	m.check("3 ⦗should be within⦘ 1 ⦗and⦘ 2")
+	as.Within(0.5, 1, 2)
+	m.check("float64(0.5) ⦗should be within⦘ 1 ⦗and⦘ 2")
+	as.WithinExcl(2, 1, 2)
+	m.check("2 ⦗should be strictly between⦘ 1 ⦗and⦘ 2")
+	as.WithinExcl(1, 1, 2)
+	m.check("1 ⦗should be strictly between⦘ 1 ⦗and⦘ 2")
+
+	as.ElementsMatch([]string{"a", "b", "b", "c", "c"}, []string{"c", "b", "d", "c", "c"})
+	m.check(`
+[]string/* len=5 cap=5 */{
+    "a",
+    "b",
+    "b",
+    "c",
+    "c",
+}
+
+ ⦗should match the elements of⦘ 
+
+[]string/* len=5 cap=5 */{
+    "c",
+    "b",
+    "d",
+    "c",
+    "c",
+}
+
+ ⦗only in the first⦘ 
+
+gop.Arr/* len=1 cap=1 */{
+    "a",
+}
+
+ ⦗only in the second⦘ 
+
+gop.Arr/* len=1 cap=1 */{
+    "d",
+}
+
+ ⦗different counts⦘ 
+
+"b" ⦗appears⦘ 2 ⦗vs⦘ 1
+
+"c" ⦗appears⦘ 2 ⦗vs⦘ 3`)
+
+	list := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	as.HasFunc(list, func(i int) bool { return list[i] > 20 })
+	m.check(`
+ ⦗no item satisfies the predicate, the first⦘ 10 ⦗of⦘ 12 ⦗items are⦘ 
+
+[]int/* len=10 cap=10 */{
+    1,
+    2,
+    3,
+    4,
+    5,
+    6,
+    7,
+    8,
+    9,
+    10,
+}`)
+	as.HasFunc([]int{1}, func(i int) bool { return false })
+	m.check(`
+ ⦗no item satisfies the predicate in⦘ 
+
+[]int/* len=1 cap=1 */{
+    1,
+}`)
+	as.AllFunc([]string{"a", "b", "c"}, func(i int) bool { return i < 1 })
+	m.check(` ⦗item at index⦘ 1 ⦗doesn't satisfy the predicate⦘ "b"`)
+	as.HasFunc("abc", func(i int) bool { return true })
+	m.check(`"abc" ⦗should be the kind of⦘ slice or array`)
+	as.AllFunc(1, func(i int) bool { return true })
+	m.check(`1 ⦗should be the kind of⦘ slice or array`)
+	as.ElementsMatch(map[int]int{}, []int{})
+	m.check("map[int]int{\n} ⦗should be the kind of⦘ slice or array")
+	as.ElementsMatch([]int{}, nil)
+	m.check(`nil ⦗should be the kind of⦘ slice or array`)
+
+	as.EqReader(strings.NewReader("abc"), strings.NewReader("axc"))
+	m.check(`"abc" ⦗not ==⦘ "axc"`)
+	as.EqReader(bytes.NewReader([]byte{0xff, 1, 2}), bytes.NewReader([]byte{0xff, 1, 3, 4}))
+	m.check(" ⦗contents mismatch at offset⦘ 2 ⦗actual⦘ ff0102 ⦗expected⦘ ff010304")
+	as.EqReader(iotest.ErrReader(errors.New("boom")), strings.NewReader(""))
+	m.check(" ⦗failed to read the⦘ actual ⦗reader⦘ boom")
+	as.EqReader(strings.NewReader(""), iotest.ErrReader(errors.New("boom")))
+	m.check(" ⦗failed to read the⦘ expected ⦗reader⦘ boom")
+
+	as.Assert(3%2 == 0, "%d ⦗should be even⦘", 3)
+	m.check("3 ⦗should be even⦘")
+	as.Assert(false, "100%% ⦗done⦘ ⦗")
+	m.check("100% ⦗done⦘ ⦗")
+
+	as.SortedAsc([]int{1, 3, 2, 0})
+	m.check("3 ⦗at index⦘ 1 ⦗should not be before⦘ 2 ⦗at index⦘ 2")
+	as.SortedDesc([]int{1, 3})
+	m.check("1 ⦗at index⦘ 0 ⦗should not be before⦘ 3 ⦗at index⦘ 1")
+	as.Sorted(1, func(i, j int) bool { return false })
+	m.check("1 ⦗should be the kind of⦘ slice or array")
+	as.SortedAsc(nil)
+	m.check("nil ⦗should be the kind of⦘ slice or array")
+	as.SortedDesc("abc")
+	m.check(`"abc" ⦗should be the kind of⦘ slice or array`)
+
 	as.Err(nil)
 	m.check(" ⦗last value⦘ nil ⦗should be <error>⦘ ")
 	as.Panic(func() {})
@@ -197,6 +424,113 @@ got_test.data/* len=2 */{
 	as.Err(1)
 	m.check(" ⦗last value⦘ 1 ⦗should be <error>⦘ ")
 
+	as.EqErr(nil, "a")
+	m.check(" ⦗last value⦘ nil ⦗should be <error>⦘ ")
+	as.EqErr(fmt.Errorf("open: %w", errors.New("not found")), "denied")
+	m.check(`
+"open: not found"
+
+ ⦗should contain⦘ 
+
+"denied"
+
+ ⦗error chain⦘ 
+
+[]string/* len=2 cap=2 */{
+    "open: not found",
+    "not found",
+}`)
+	as.FileExists("not-exists")
+	m.check(` ⦗file⦘ not-exists ⦗should exist, but⦘ stat not-exists: no such file or directory`)
+	as.FileExists("lib")
+	m.check(` ⦗file⦘ lib ⦗should exist, but⦘ is a directory`)
+	as.DirExists("not-exists")
+	m.check(` ⦗directory⦘ not-exists ⦗should exist, but⦘ stat not-exists: no such file or directory`)
+	as.DirExists("go.mod")
+	m.check(` ⦗directory⦘ go.mod ⦗should exist, but⦘ is not a directory`)
+	as.FileContains("not-exists", "a")
+	m.check(` ⦗file⦘ not-exists ⦗should exist, but⦘ open not-exists: no such file or directory`)
+	as.FileContains("lib", "a")
+	m.check(` ⦗file⦘ lib ⦗can't read⦘ read lib: is a directory`)
+	as.FileContains("fixtures/golden/text.txt", "line 3")
+	m.check("\n ⦗file⦘ fixtures/golden/text.txt ⦗should contain⦘ \"line 3\"\n\n ⦗content⦘ \n\n" +
+		"`line 1\nline 2\n`")
+
+	as.ErrorContains(nil, "%d", 1)
+	m.check(" ⦗last value⦘ nil ⦗should be <error>⦘ ")
+	as.ErrorContains(errors.New("a"), "code: %d", 1)
+	m.check(`
+"a"
+
+ ⦗should contain⦘ 
+
+"code: 1"
+
+ ⦗error chain⦘ 
+
+[]string/* len=1 cap=1 */{
+    "a",
+}`)
+	as.EqErrExact(nil, errors.New("a"))
+	m.check(`
+nil
+
+ ⦗not ==⦘ 
+
+"a"
+
+ ⦗error chain⦘ 
+
+[]string/* len=0 cap=0 */{
+}`)
+
+	as.AllOf(
+		func(g got.G) { g.Eq(1, 1) },
+		func(g got.G) { g.Eq(1, 2) },
+		func(g got.G) { g.Desc("third").Must().Lt(2, 1); g.Eq(1, 2) },
+	)
+	m.check(`
+ ⦗all of the conditions should pass⦘ 
+
+ ⦗condition 1 failed⦘ 1 ⦗not ==⦘ 2
+
+ ⦗condition 2 failed⦘ third
+2 ⦗not <⦘ 1`)
+
+	as.AnyOf(func(g got.G) { g.Eq(1, 2) }, func(g got.G) { g.Gt(1, 2) })
+	m.check(`
+ ⦗any of the conditions should pass⦘ 
+
+ ⦗condition 0 failed⦘ 1 ⦗not ==⦘ 2
+
+ ⦗condition 1 failed⦘ 1 ⦗not >⦘ 2`)
+
+	as.Eventually(10*time.Millisecond, time.Hour, func(g got.G) {
+		g.Eq(1, 2)
+	})
+	m.check(` ⦗timed out after⦘ 1 ⦗attempts, the last failure⦘ 1 ⦗not ==⦘ 2`)
+
+	ctx := as.Context()
+	ctx.Cancel()
+	as.EventuallyCtx(ctx, time.Millisecond, func(g got.G) {})
+	m.check(` ⦗context cancelled after⦘ 0 ⦗attempts⦘ `)
+
+	ch := make(chan int, 1)
+	as.Nil(as.Recv(ch, time.Millisecond))
+	m.check(` ⦗nothing received from the channel within⦘ gop.Duration("1ms")`)
+	ch <- 1
+	as.NoRecv(ch, time.Second)
+	m.check(` ⦗should receive nothing from the channel within⦘ gop.Duration("1s") ⦗but received⦘ 1`)
+	close(ch)
+	as.Nil(as.Recv(ch, time.Second))
+	m.check(` ⦗channel closed⦘ `)
+	as.NoRecv(ch, time.Second)
+	m.check(` ⦗channel closed⦘ `)
+	as.Nil(as.Recv(1, time.Second))
+	m.check(`1 ⦗should be the kind of⦘ chan`)
+	as.NoRecv(nil, time.Second)
+	m.check(`nil ⦗should be the kind of⦘ chan`)
+
 	func() {
 		defer func() {
 			_ = recover()
@@ -297,6 +631,17 @@ func TestAssertionColor(t *testing.T) {
 
 	g.Eq("abc", "axc")
 	m.checkWithStyle(`<33>"a<39><41>b<49><33>c"<39> <31><4>⦗not ==⦘<24><39> <33>"a<39><42>x<49><33>c"<39>`, true)
+
+	g.Eq("天a", "天b")
+	m.checkWithStyle(`<33>"天<39><41>a<49><33>"<39> <31><4>⦗not ==⦘<24><39> <33>"天<39><42>b<49><33>"<39>`, true)
+
+	g.Assert(false, "%d ⦗should be even⦘", 3)
+	m.checkWithStyle(`3 <31><4>⦗should be even⦘<24><39>`, true)
+
+	g.Eq(1, "a")
+	m.checkWithStyle(`<36>in<39><41>t<49><36><39>(<32><39><41>1<49><32><39>) <31><4>⦗not ==⦘<24><39> `+
+		`<36><39><42>s<49><36><39><42>t<49><36><39><42>r<49><36>in<39><42>g<49><36><39>(`+
+		`<33><39><42>"<49><33><39><42>a<49><33><39><42>"<49><33><39>)`, true)
 }
 
 func TestCustomAssertionError(t *testing.T) {
@@ -312,3 +657,426 @@ func TestCustomAssertionError(t *testing.T) {
 	g.Eq(1, 2)
 	m.check("custom eq")
 }
+
+func TestAssertionBytesDiff(t *testing.T) {
+	m := &mock{t: t}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, diff.ThemeNone)
+
+	x := []byte("\xff line one\nline two\nline three\n")
+	y := []byte("\xff line one\nline 2\nline three\n")
+	g.Eq(x, y)
+	m.check(`
+"\xff line one"
+"line two"
+"line three"
+""
+
+ ⦗not ==⦘ 
+
+"\xff line one"
+"line 2"
+"line three"
+""
+
+@@ diff chunk @@
+1 1   "\xff line one"
+2   - "line two"
+  2 + "line 2"
+3 3   "line three"
+
+`)
+}
+
+func TestFileContainsDiff(t *testing.T) {
+	m := &mock{t: t}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, diff.ThemeNone)
+
+	g.FileContains("fixtures/golden/text.txt", "line 1\nline 3")
+	m.check("\n ⦗file⦘ fixtures/golden/text.txt ⦗should contain⦘ `line 1\nline 3`\n\n" +
+		" ⦗content⦘ \n\n" +
+		"`line 1\nline 2\n`\n\n" +
+		"@@ diff chunk @@\n1 1   line 1\n2   - line 2\n3   - \n  2 + line 3\n\n")
+}
+
+func TestEqualHook(t *testing.T) {
+	m := &mock{t: t}
+
+	type msg struct {
+		ID    int
+		cache []byte
+	}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+	g.EqualHook = func(x, y interface{}) (handled, equal bool) {
+		a, ok := x.(msg)
+		if !ok {
+			return false, false
+		}
+		b := y.(msg)
+		return true, a.ID == b.ID
+	}
+
+	g.Eq(msg{1, []byte("a")}, msg{1, nil})
+	g.Equal(msg{1, []byte("a")}, msg{1, nil})
+	g.Neq(msg{1, nil}, msg{2, nil})
+	g.Eq(1, 1.0)
+
+	expected := "\n" +
+		"got_test.msg/* len=2 */{\n    ID: 1,\n    cache: []byte(\"\"),\n}\n\n" +
+		" ⦗not ==⦘ \n\n" +
+		"got_test.msg/* len=2 */{\n    ID: 2,\n    cache: []byte(\"\"),\n}"
+
+	g.Eq(msg{1, nil}, msg{2, nil})
+	m.check(expected)
+	g.Equal(msg{1, nil}, msg{2, nil})
+	m.check(expected)
+	g.Neq(msg{1, nil}, msg{1, []byte("a")})
+	m.check("\n" +
+		"got_test.msg/* len=2 */{\n    ID: 1,\n    cache: []byte(\"\"),\n}\n\n" +
+		" ⦗==⦘ \n\n" +
+		"got_test.msg/* len=2 */{\n    ID: 1,\n    cache: []byte(\"a\"),\n}")
+
+	g.Eq(g.Diff(msg{1, nil}, msg{1, []byte("a")}), "")
+	g.Eq(g.Diff(msg{1, nil}, msg{2, nil}), expected)
+
+	// the hook is consulted before the identical values are treated as equal
+	g.EqualHook = func(x, y interface{}) (handled, equal bool) { return true, false }
+	g.Eq(1, 1)
+	m.check("1 ⦗not ==⦘ 1")
+	g.Eq(g.Diff(1, 1), "1 ⦗not ==⦘ 1")
+}
+
+func TestEqualNaN(t *testing.T) {
+	m := &mock{t: t}
+
+	type point struct {
+		X, Y float64
+	}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+
+	g.Eq(math.NaN(), math.NaN())
+	m.check("float64(NaN) ⦗not ==⦘ float64(NaN)")
+
+	g.EqualHook = got.EqualNaN
+	g.Eq(math.NaN(), math.NaN())
+	g.Eq(point{1, math.NaN()}, point{1, math.NaN()})
+	g.Eq(1, 1.0)
+
+	g.Eq(point{1, math.NaN()}, point{2, math.NaN()})
+	m.check("\n" +
+		"got_test.point/* len=2 */{\n    X: float64(1),\n    Y: float64(NaN),\n}\n\n" +
+		" ⦗not ==⦘ \n\n" +
+		"got_test.point/* len=2 */{\n    X: float64(2),\n    Y: float64(NaN),\n}")
+
+	g.EqStrict(point{1, 2}, point{1, 2})
+	g.EqStrict(point{1, math.NaN()}, point{1, math.NaN()})
+	m.check("\n" +
+		"got_test.point/* len=2 */{\n    X: float64(1),\n    Y: float64(NaN),\n}\n\n" +
+		" ⦗not ==⦘ \n\n" +
+		"got_test.point/* len=2 */{\n    X: float64(1),\n    Y: float64(NaN),\n}")
+}
+
+func TestEqTruncated(t *testing.T) {
+	m := &mock{t: t}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+
+	gop.StringPreview = 4
+	defer func() { gop.StringPreview = 0 }()
+
+	// only the message is truncated
+	g.Eq("abc123def", "abc456def")
+	m.check(`"ab...ef"/* truncated len=9 */ ⦗not ==⦘ "ab...ef"/* truncated len=9 */`)
+}
+
+func TestDiff(t *testing.T) {
+	g := got.T(t)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, diff.ThemeNone)
+
+	g.Eq(g.Diff(1, 1.0), "")
+	g.Eq(g.Diff("a\nb", "a\nc"), ""+
+		"\n`a\nb`\n\n ⦗not ==⦘ \n\n`a\nc`\n\n"+
+		"@@ diff chunk @@\n1 1   `a\n2   - b`\n  2 + c`\n\n")
+}
+
+func TestMapDiff(t *testing.T) {
+	m := &mock{t: t}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, diff.ThemeNone)
+
+	g.Eq(
+		map[string]int{"a": 1, "b": 2, "c": 3, "d": 4},
+		map[string]float64{"a": 1, "b": 20, "c": 3, "e": 5},
+	)
+	m.check(`
+map[string]int/* len=4 */{
+    "a": 1,
+    "b": 2,
+    "c": 3,
+    "d": 4,
+}
+
+ ⦗not ==⦘ 
+
+map[string]float64/* len=4 */{
+    "a": float64(1),
+    "b": float64(20),
+    "c": float64(3),
+    "e": float64(5),
+}
+
+ ⦗different values of keys⦘ 
+
+gop.Arr/* len=1 cap=1 */{
+    "b",
+}
+
+ ⦗keys only in the first⦘ 
+
+gop.Arr/* len=1 cap=1 */{
+    "d",
+}
+
+ ⦗keys only in the second⦘ 
+
+gop.Arr/* len=1 cap=1 */{
+    "e",
+}`)
+
+	g.Eq(map[int]int{3: 1, 1: 1, 2: 1}, map[int]int{3: 2, 1: 2, 2: 2})
+	m.check(`
+map[int]int/* len=3 */{
+    1: 1,
+    2: 1,
+    3: 1,
+}
+
+ ⦗not ==⦘ 
+
+map[int]int/* len=3 */{
+    1: 2,
+    2: 2,
+    3: 2,
+}
+
+ ⦗different values of keys⦘ 
+
+gop.Arr/* len=3 cap=3 */{
+    1,
+    2,
+    3,
+}`)
+
+	g.Eq(map[int]int{1: 1, 2: 1}, map[int64]int{1: 1, 2: 2})
+	m.check(`
+map[int]int/* len=2 */{
+    1: 1,
+    2: 1,
+}
+
+ ⦗not ==⦘ 
+
+map[int64]int/* len=2 */{
+    int64(1): 1,
+    int64(2): 2,
+}
+
+ ⦗different values of keys⦘ 
+
+gop.Arr/* len=1 cap=1 */{
+    2,
+}`)
+
+	g.Eq(map[string]int{"a": 1}, map[string]float64{"a": 1})
+	m.check(`
+map[string]int{
+    "a": 1,
+}
+
+ ⦗not ==⦘ 
+
+map[string]float64{
+    "a": float64(1),
+}
+
+@@ diff chunk @@
+1   - map[string]int{
+2   -     "a": 1,
+  1 + map[string]float64{
+  2 +     "a": float64(1),
+3 3   }
+
+`)
+}
+
+func TestStructFieldsDiff(t *testing.T) {
+	m := &mock{t: t}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, diff.ThemeNone)
+
+	type user struct {
+		ID    int
+		Name  string
+		Email string
+		Age   int
+		Admin bool
+	}
+
+	g.Eq(
+		user{1, "Jack", "jack@example.com", 20, false},
+		user{1, "Jonathan", "jack@example.com", 21, false},
+	)
+	m.check(`
+got_test.user/* len=5 */{
+    ID: 1,
+    Name: "Jack",
+    Email: "jack@example.com"/* len=16 */,
+    Age: 20,
+    Admin: false,
+}
+
+ ⦗not ==⦘ 
+
+got_test.user/* len=5 */{
+    ID: 1,
+    Name: "Jonathan",
+    Email: "jack@example.com"/* len=16 */,
+    Age: 21,
+    Admin: false,
+}
+
+@@ diff chunk @@
+2 2       ID: 1,
+3   -     Name: "Jack",
+  3 +     Name: "Jonathan",
+4 4       Email: "jack@example.com"/* len=16 */,
+5   -     Age: 20,
+  5 +     Age: 21,
+6 6       Admin: false,
+
+`)
+}
+
+func TestEqTypeMismatch(t *testing.T) {
+	m := &mock{t: t}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+
+	type myInt int
+
+	g.Equal(int(5), int64(5))
+	m.check("int(5) ⦗not ==⦘ int64(5)")
+
+	g.Equal(myInt(5), uint(5))
+	m.check("got_test.myInt(5) ⦗not ==⦘ uint(5)")
+
+	g.Equal(5*time.Second, "5s")
+	m.check(`gop.Duration("5s") ⦗not ==⦘ string("5s")`)
+
+	gop.ShowKind = true
+	g.Equal(int(5), int64(5))
+	gop.ShowKind = false
+	m.check("/* kind=int */int(5) ⦗not ==⦘ /* kind=int64 */int64(5)")
+
+	g.Eq(1, 2)
+	m.check("1 ⦗not ==⦘ 2")
+
+	g.Eq([]int{1}, []int64{1, 2})
+	m.check("\n[]int/* len=1 cap=1 */{\n    1,\n}\n\n ⦗not ==⦘ \n\n[]int64/* len=2 cap=2 */{\n    int64(1),\n    int64(2),\n}")
+}
+
+func TestEqNormalized(t *testing.T) {
+	m := &mock{t: t}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+
+	sorted := func(v interface{}) interface{} {
+		list := append([]string{}, v.([]string)...)
+		sort.Strings(list)
+		return list
+	}
+
+	x := []string{"b", "a"}
+	g.EqNormalized(x, []string{"a", "b"}, sorted)
+	g.Eq(x, []string{"b", "a"})
+
+	g.EqNormalized([]string{"b"}, []string{"a"}, sorted)
+	m.check("\n[]string/* len=1 cap=1 */{\n    \"b\",\n}\n\n ⦗not ==⦘ \n\n[]string/* len=1 cap=1 */{\n    \"a\",\n}")
+}
+
+func TestEqTrimmed(t *testing.T) {
+	m := &mock{t: t}
+
+	g := got.New(m)
+	g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, diff.ThemeNone)
+
+	g.EqTrimmed("a  \nb\t\r\n\n", "a\nb")
+	g.EqTrimmed("", "\n \n")
+
+	g.EqTrimmed("a \nb\n", "a\nc\n")
+	m.check(`
+`+"`"+`a
+b`+"`"+`
+
+ ⦗not ==⦘ 
+
+`+"`"+`a
+c`+"`"+`
+
+@@ diff chunk @@
+1 1   `+"`"+`a
+2   - b`+"`"+`
+  2 + c`+"`"+`
+
+`)
+}
+
+func TestEqIdentical(t *testing.T) {
+	g := got.T(t)
+
+	type box struct{ V interface{} }
+
+	big := [1000]int{1}
+	g.Eq(big, big)
+	g.Eq(&big, &big)
+	g.Eq(box{[]int{1}}, box{[]int{1}})
+	g.Eq(nil, nil)
+
+	m := &mock{t: t}
+	mg := got.New(m)
+	mg.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+	mg.Eq(box{1}, box{2})
+	m.check("\ngot_test.box{\n    V: 1,\n}\n\n ⦗not ==⦘ \n\ngot_test.box{\n    V: 2,\n}")
+}
+
+func BenchmarkEq(b *testing.B) {
+	g := got.T(b)
+
+	// box the copies once, so that the copy of the array isn't measured
+	var x, y interface{} = [10000]int{}, [10000]int{}
+
+	b.Run("identical", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Eq(x, y)
+		}
+	})
+
+	// the comparison that Eq falls back to when x and y aren't identical
+	b.Run("smart compare", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Zero(utils.SmartCompare(x, y))
+		}
+	})
+}
@@ -1,8 +1,13 @@
 package got
 
 import (
+	"fmt"
 	"reflect"
+	"runtime"
 	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
 )
 
 // Only run tests with it
@@ -12,7 +17,7 @@ type Only struct{}
 type Skip struct{}
 
 // Each runs each exported method Fn on type Ctx as a subtest of t.
-// The iteratee can be a struct Ctx or:
+// The iteratee can be a struct Ctx, a pointer to struct Ctx, or:
 //
 //     iteratee(t Testable) (ctx Ctx)
 //
@@ -20,20 +25,72 @@ type Skip struct{}
 //
 //      ctx.Fn()
 //
-// If iteratee is Ctx, its G field will be set to New(t) for each test.
+// The Fn can have either a value receiver or a pointer receiver.
+//
+// If iteratee is Ctx or *Ctx, it will be shallow copied for each test, and the G field of the copy will be set to New(t).
 // Any Fn that has the same name with the embedded one will be ignored.
+// To unwrap the (value, error) results inside a Fn, use Must with the ctx, such as:
+//
+//     n := got.Must(strconv.Atoi(s))(ctx)
 func Each(t Testable, iteratee interface{}) (count int) {
 	t.Helper()
+	return each(t, iteratee, 0).Total
+}
+
+// EachTimeout is the same as Each, but each Fn will fail if it doesn't finish within the timeout,
+// so that a stuck Fn won't make "go test -timeout" kill the whole test binary.
+// The Fn keeps running in its goroutine after the timeout, it may leak if it ignores the cancellation
+// of G.Context, which will be canceled when the subtest ends. The calls to the G of the Ctx after the timeout,
+// such as Log or Fail, will be dropped, because the subtest may have ended.
+func EachTimeout(t Testable, iteratee interface{}, timeout time.Duration) (count int) {
+	t.Helper()
+	return each(t, iteratee, timeout).Total
+}
+
+// Stats of the subtests run by EachStats
+type Stats struct {
+	// Total is the number of the methods that have been run, the ones skipped by Skip are excluded
+	Total int
+	// Failed is the number of the methods that failed
+	Failed int
+}
+
+// Passed is the number of the methods that didn't fail
+func (s Stats) Passed() int {
+	return s.Total - s.Failed
+}
+
+// String returns a summary like "ran 12, 2 failed"
+func (s Stats) String() string {
+	return fmt.Sprintf("ran %d, %d failed", s.Total, s.Failed)
+}
+
+// EachStats is the same as Each, but it returns the stats of the outcomes of the subtests.
+// The subtests that call Parallel are only counted after they finish, they may be missing from the returned stats.
+func EachStats(t Testable, iteratee interface{}) Stats {
+	t.Helper()
+	return each(t, iteratee, 0)
+}
+
+func each(t Testable, iteratee interface{}, timeout time.Duration) Stats {
+	t.Helper()
 
 	itVal := normalizeIteratee(t, iteratee)
 
 	ctxType := itVal.Type().Out(0)
+	if ctxType.Kind() != reflect.Ptr {
+		// to include the methods with pointer receivers
+		ctxType = reflect.PtrTo(ctxType)
+	}
 
 	methods := filterMethods(ctxType)
 
 	runVal := reflect.ValueOf(t).MethodByName("Run")
 	cbType := runVal.Type().In(1)
 
+	lock := sync.Mutex{}
+	stats := Stats{}
+
 	for _, m := range methods {
 		// because the callback is in another goroutine, we create closures for each loop
 		method := m
@@ -43,13 +100,62 @@ func Each(t Testable, iteratee interface{}) (count int) {
 			reflect.MakeFunc(cbType, func(args []reflect.Value) []reflect.Value {
 				t := args[0].Interface().(Testable)
 				doSkip(t, method)
-				count++
+
+				lock.Lock()
+				stats.Total++
+				lock.Unlock()
+
+				// use defer because FailNow will exit the goroutine
+				defer func() {
+					if t.Failed() {
+						lock.Lock()
+						stats.Failed++
+						lock.Unlock()
+					}
+				}()
+
 				res := itVal.Call(args)
-				return callMethod(t, method, res[0])
+				return callMethod(t, method, addressable(res[0]), timeout)
 			}),
 		})
 	}
-	return
+
+	lock.Lock()
+	defer lock.Unlock()
+	return stats
+}
+
+// EachCase runs fn with each case as a subtest of t, which brings table-driven tests to the subtests of Each.
+// The subtest will be named by the Name field of the case if the case is a struct with it,
+// otherwise the index of the case will be used.
+func EachCase[C any](t Testable, cases []C, fn func(g G, c C)) {
+	t.Helper()
+
+	runVal := reflect.ValueOf(t).MethodByName("Run")
+	cbType := runVal.Type().In(1)
+
+	for i, c := range cases {
+		// because the callback is in another goroutine, we create closures for each loop
+		c := c
+
+		runVal.Call([]reflect.Value{
+			reflect.ValueOf(caseName(i, c)),
+			reflect.MakeFunc(cbType, func(args []reflect.Value) []reflect.Value {
+				fn(New(args[0].Interface().(Testable)), c)
+				return nil
+			}),
+		})
+	}
+}
+
+func caseName(i int, c interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(c))
+	if v.Kind() == reflect.Struct {
+		if name := v.FieldByName("Name"); name.Kind() == reflect.String {
+			return name.String()
+		}
+	}
+	return strconv.Itoa(i)
 }
 
 func normalizeIteratee(t Testable, iteratee interface{}) reflect.Value {
@@ -78,14 +184,22 @@ func normalizeIteratee(t Testable, iteratee interface{}) reflect.Value {
 		fnType := reflect.FuncOf([]reflect.Type{reflect.TypeOf(t)}, []reflect.Type{itType}, false)
 		structVal := itVal
 		itVal = reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
-			sub := args[0].Interface().(Testable)
-			as := reflect.ValueOf(New(sub))
-
-			c := reflect.New(itType).Elem()
-			c.Set(structVal)
-			try(func() { c.FieldByName("G").Set(as) })
+			return []reflect.Value{newCtx(args[0], structVal).Elem()}
+		})
+		fail = false
 
-			return []reflect.Value{c}
+	case reflect.Ptr:
+		if itType.Elem().Kind() != reflect.Struct {
+			break
+		}
+		if itVal.IsNil() {
+			t.Logf("iteratee <%v> shouldn't be a nil pointer", itType)
+			t.FailNow()
+		}
+		fnType := reflect.FuncOf([]reflect.Type{reflect.TypeOf(t)}, []reflect.Type{itType}, false)
+		structVal := itVal.Elem()
+		itVal = reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+			return []reflect.Value{newCtx(args[0], structVal)}
 		})
 		fail = false
 	}
@@ -97,7 +211,28 @@ func normalizeIteratee(t Testable, iteratee interface{}) reflect.Value {
 	return itVal
 }
 
-func callMethod(t Testable, method reflect.Method, receiver reflect.Value) []reflect.Value {
+// newCtx returns a pointer to the copy of structVal, its G field will be set to New(t)
+func newCtx(t reflect.Value, structVal reflect.Value) reflect.Value {
+	as := reflect.ValueOf(New(t.Interface().(Testable)))
+
+	c := reflect.New(structVal.Type())
+	c.Elem().Set(structVal)
+	try(func() { c.Elem().FieldByName("G").Set(as) })
+
+	return c
+}
+
+// addressable returns a pointer to the copy of v if v is not a pointer
+func addressable(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		return v
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p
+}
+
+func callMethod(t Testable, method reflect.Method, receiver reflect.Value, timeout time.Duration) []reflect.Value {
 	args := make([]reflect.Value, method.Type.NumIn())
 	args[0] = receiver
 
@@ -105,25 +240,131 @@ func callMethod(t Testable, method reflect.Method, receiver reflect.Value) []ref
 		args[i] = reflect.New(method.Type.In(i)).Elem()
 	}
 
-	defer func() {
-		if err := recover(); err != nil {
-			t.Logf("[panic] %v\n%s", err, debug.Stack())
-			t.Fail()
-		}
+	call := func(t Testable) {
+		defer func() {
+			if err := recover(); err != nil {
+				t.Logf("[panic] %v\n%s", err, debug.Stack())
+				t.Fail()
+			}
+		}()
+
+		method.Func.Call(args)
+	}
+
+	if timeout <= 0 {
+		call(t)
+		return []reflect.Value{}
+	}
+
+	tt := &timeoutTestable{Testable: t}
+	useTestable(receiver, t, tt)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		call(tt)
 	}()
 
-	method.Func.Call(args)
+	tmr := time.NewTimer(timeout)
+	defer tmr.Stop()
+
+	select {
+	case <-done:
+	case <-tmr.C:
+		tt.stop()
+		t.Logf("[timeout] %s didn't finish within %v, it may leak if it ignores the cancellation of the context",
+			method.Name, timeout)
+		t.Fail()
+	}
 
 	return []reflect.Value{}
 }
 
+// useTestable replaces t with tt in the G field of the ctx that receiver points to
+func useTestable(receiver reflect.Value, t, tt Testable) {
+	f := receiver.Elem().FieldByName("G")
+	if !f.IsValid() || f.Type() != reflect.TypeOf(G{}) {
+		return
+	}
+
+	g := f.Interface().(G)
+	if g.Testable == t {
+		g.Testable = tt
+	}
+	if g.Assertions.Testable == t {
+		g.Assertions.Testable = tt
+	}
+	if g.Utils.Testable == t {
+		g.Utils.Testable = tt
+	}
+	f.Set(reflect.ValueOf(g))
+}
+
+// timeoutTestable drops the calls after the timeout of EachTimeout, because the subtest may have finished,
+// and the testing package panics when the t of a finished test is used.
+// After the timeout, FailNow and SkipNow only exit the goroutine of the method.
+type timeoutTestable struct {
+	Testable
+	lock    sync.Mutex
+	stopped bool
+}
+
+func (tt *timeoutTestable) stop() {
+	tt.lock.Lock()
+	defer tt.lock.Unlock()
+	tt.stopped = true
+}
+
+// do calls fn with the lock if tt isn't stopped, it returns false if fn isn't called
+func (tt *timeoutTestable) do(fn func()) bool {
+	tt.lock.Lock()
+	defer tt.lock.Unlock()
+	if tt.stopped {
+		return false
+	}
+	fn()
+	return true
+}
+
+// Cleanup interface
+func (tt *timeoutTestable) Cleanup(f func()) { tt.do(func() { tt.Testable.Cleanup(f) }) }
+
+// Fail interface
+func (tt *timeoutTestable) Fail() { tt.do(tt.Testable.Fail) }
+
+// FailNow interface
+func (tt *timeoutTestable) FailNow() {
+	if !tt.do(tt.Testable.FailNow) {
+		runtime.Goexit()
+	}
+}
+
+// SkipNow interface
+func (tt *timeoutTestable) SkipNow() {
+	if !tt.do(tt.Testable.SkipNow) {
+		runtime.Goexit()
+	}
+}
+
+// Logf interface
+func (tt *timeoutTestable) Logf(format string, args ...interface{}) {
+	tt.do(func() { tt.Testable.Logf(format, args...) })
+}
+
 func filterMethods(typ reflect.Type) []reflect.Method {
+	structType := typ
+	if typ.Kind() == reflect.Ptr {
+		structType = typ.Elem()
+	}
+
 	embedded := map[string]struct{}{}
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
 		if field.Anonymous {
-			for j := 0; j < field.Type.NumMethod(); j++ {
-				embedded[field.Type.Method(j).Name] = struct{}{}
+			for _, ft := range []reflect.Type{field.Type, reflect.PtrTo(field.Type)} {
+				for j := 0; j < ft.NumMethod(); j++ {
+					embedded[ft.Method(j).Name] = struct{}{}
+				}
 			}
 		}
 	}
@@ -1,9 +1,13 @@
 package got_test
 
 import (
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ysmood/got"
+	"github.com/ysmood/got/lib/gop"
 )
 
 func TestEach(t *testing.T) {
@@ -28,6 +32,67 @@ func (c StructVal) ExtraInOut(int) int {
 func (c StructVal) TestSkip(got.Skip) {
 }
 
+func TestEachPtr(t *testing.T) {
+	count := got.Each(t, &PtrVal{val: 1})
+	got.New(t).Eq(count, 2)
+}
+
+type PtrVal struct {
+	got.G
+	val int
+}
+
+func (c *PtrVal) Mutate() {
+	c.val++
+	c.Eq(c.val, 2)
+}
+
+func (c PtrVal) Value() {
+	c.Eq(c.val, 1)
+}
+
+func TestEachPtrReceiver(t *testing.T) {
+	count := got.Each(t, PtrReceiver{val: 1})
+	got.New(t).Eq(count, 2)
+}
+
+type PtrReceiver struct {
+	got.G
+	val int
+}
+
+func (c *PtrReceiver) Fn() {
+	c.val++
+	c.Eq(c.val, 2)
+}
+
+func (c PtrReceiver) Value() {
+	c.Eq(c.val, 1)
+}
+
+func TestEachCase(t *testing.T) {
+	type Case struct {
+		Name     string
+		a, b     int
+		expected int
+	}
+
+	names := []string{}
+	got.EachCase(t, []Case{
+		{"one", 1, 0, 1},
+		{"two", 1, 1, 2},
+	}, func(g got.G, c Case) {
+		names = append(names, g.Name())
+		g.Eq(c.a+c.b, c.expected)
+	})
+	got.T(t).Eq(names, []string{"TestEachCase/one", "TestEachCase/two"})
+
+	got.EachCase(t, []int{1, 1}, func(g got.G, c int) {
+		g.Eq(c, 1)
+		g.Has(g.Name(), "TestEachCase/")
+	})
+}
+
 func TestEachEmbedded(t *testing.T) {
 	got.Each(t, Container{})
 }
@@ -71,6 +136,16 @@ func TestEachErr(t *testing.T) {
 	})
 	m.check("iteratee <int> should be a struct or <func(got.Testable) Ctx>")
 
+	as.Panic(func() {
+		got.Each(m, (*Err)(nil))
+	})
+	m.check("iteratee <*got_test.Err> shouldn't be a nil pointer")
+
+	as.Panic(func() {
+		got.Each(m, new(int))
+	})
+	m.check("iteratee <*int> should be a struct or <func(got.Testable) Ctx>")
+
 	it := func() Err { return Err{} }
 	as.Panic(func() {
 		got.Each(m, it)
@@ -102,3 +177,137 @@ func (p PanicAsFailure) A() {
 
 func (p PanicAsFailure) B() {
 }
+
+func TestEachMust(t *testing.T) {
+	as := got.New(t)
+
+	got.Each(t, MustSuite{})
+
+	m := &mock{t: t}
+	it := func(t *mock) MustSuite {
+		g := got.New(t)
+		g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+		return MustSuite{g}
+	}
+	as.Eq(got.EachStats(m, it), got.Stats{Total: 1, Failed: 1})
+	as.Has(m.msg, `s: "invalid syntax"`)
+}
+
+type MustSuite struct {
+	got.G
+}
+
+func (c MustSuite) Atoi() {
+	s := "10"
+	if _, ok := c.Testable.(*mock); ok {
+		s = "x"
+	}
+	c.Eq(got.Must(strconv.Atoi(s))(c), 10)
+}
+
+func TestEachStats(t *testing.T) {
+	as := got.New(t)
+
+	m := &mock{t: t}
+	it := func(t *mock) StatsSuite { return StatsSuite{} }
+	stats := got.EachStats(m, it)
+	as.Eq(stats, got.Stats{Total: 2, Failed: 1})
+	as.Eq(stats.Passed(), 1)
+	as.Eq(stats.String(), "ran 2, 1 failed")
+}
+
+type StatsSuite struct {
+}
+
+func (s StatsSuite) A() {}
+
+func (s StatsSuite) B() {
+	panic("err")
+}
+
+func TestEachTimeout(t *testing.T) {
+	as := got.New(t)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	m := &mock{t: t}
+	it := func(t *mock) TimeoutSuite { return TimeoutSuite{release: release} }
+	as.Eq(got.EachTimeout(m, it, 10*time.Millisecond), 3)
+
+	as.True(m.failed)
+	as.Eq(m.msg, "[timeout] B didn't finish within 10ms, it may leak if it ignores the cancellation of the context")
+
+	// the panic in the goroutine is still a failure
+	m = &mock{t: t}
+	it = func(t *mock) TimeoutSuite { return TimeoutSuite{panic: true} }
+	as.Eq(got.EachTimeout(m, it, time.Second), 3)
+	as.True(m.failed)
+	as.Has(m.msg, "[panic] err")
+}
+
+func TestEachTimeoutLateCalls(t *testing.T) {
+	as := got.New(t)
+
+	release, wg := make(chan struct{}), &sync.WaitGroup{}
+
+	m := &mock{t: t, recover: true}
+	it := func(t *mock) LateSuite { return LateSuite{G: got.New(t), release: release, wg: wg} }
+	as.Eq(got.EachTimeout(m, it, 10*time.Millisecond), 2)
+	wg.Add(2)
+	close(release)
+	wg.Wait()
+
+	// the calls after the timeout are dropped, because the subtest may have finished
+	as.Eq(m.msg, ""+
+		"[timeout] A didn't finish within 10ms, it may leak if it ignores the cancellation of the context\n"+
+		"[timeout] B didn't finish within 10ms, it may leak if it ignores the cancellation of the context")
+	as.Len(m.cleanupList, 1)
+}
+
+type LateSuite struct {
+	got.G
+	release chan struct{}
+	wg      *sync.WaitGroup
+}
+
+func (s LateSuite) A() {
+	s.Cleanup(func() {})
+	s.SkipNow()
+	<-s.release
+	defer s.wg.Done()
+
+	s.Logf("late")
+	s.Cleanup(func() {})
+	s.Fail()
+	s.SkipNow()
+	s.Logf("unreachable")
+}
+
+func (s LateSuite) B() {
+	s.FailNow()
+	<-s.release
+	defer s.wg.Done()
+
+	s.FailNow()
+	s.Logf("unreachable")
+}
+
+type TimeoutSuite struct {
+	release chan struct{}
+	panic   bool
+}
+
+func (s TimeoutSuite) A() {}
+
+func (s TimeoutSuite) B() {
+	if !s.panic {
+		<-s.release
+	}
+}
+
+func (s TimeoutSuite) C() {
+	if s.panic {
+		panic("err")
+	}
+}
@@ -0,0 +1,51 @@
+package got
+
+import (
+	"os"
+
+	"github.com/ysmood/got/lib/gop"
+)
+
+// WriteGolden writes data to the golden file at path when the env var UPDATE_GOLDEN is set, otherwise it does nothing.
+// The data can be a string or []byte, other values will be dumped via gop.Plain .
+func (g G) WriteGolden(path string, data interface{}) {
+	g.Helper()
+
+	if os.Getenv("UPDATE_GOLDEN") == "" {
+		return
+	}
+
+	f := g.Open(true, path)
+	defer func() { g.Utils.err(f.Close()) }()
+	_, err := f.WriteString(goldenStr(data))
+	g.Utils.err(err)
+}
+
+// AssertGolden asserts that data equals the content of the golden file at path,
+// the data will be converted the same way as WriteGolden. Usually, call WriteGolden before it, such as:
+//     g.WriteGolden("fixtures/out.txt", out)
+//     g.AssertGolden("fixtures/out.txt", out)
+func (g G) AssertGolden(path string, data interface{}) {
+	g.Helper()
+
+	f := g.Open(false, path)
+	defer func() { g.Utils.err(f.Close()) }()
+
+	expected := g.Read(f).String()
+	actual := goldenStr(data)
+	if actual == expected {
+		return
+	}
+	g.Assertions.err(AssertionGolden, path, actual, expected)
+}
+
+func goldenStr(data interface{}) string {
+	switch v := data.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return gop.Plain(v)
+	}
+}
@@ -0,0 +1,51 @@
+package got_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/got/lib/diff"
+	"github.com/ysmood/got/lib/gop"
+)
+
+func TestGolden(t *testing.T) {
+	g := got.T(t)
+
+	t.Setenv("UPDATE_GOLDEN", "")
+	g.WriteGolden("fixtures/golden/text.txt", "changed")
+	g.AssertGolden("fixtures/golden/text.txt", "line 1\nline 2\n")
+	g.AssertGolden("fixtures/golden/text.txt", []byte("line 1\nline 2\n"))
+
+	p := filepath.Join("tmp", "golden", g.RandStr(8)+".txt")
+	t.Setenv("UPDATE_GOLDEN", "true")
+	g.WriteGolden(p, map[string]int{"a": 1})
+	g.AssertGolden(p, map[string]int{"a": 1})
+	g.Eq(g.Read(g.Open(false, p)).String(), "map[string]int{\n    \"a\": 1,\n}")
+	g.Nil(os.Remove(p))
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	g := got.T(t)
+
+	m := got.MockTestable("golden")
+	check := func(dt diff.Theme) string {
+		g.False(m.Check(func(g got.G) {
+			g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, dt)
+			g.AssertGolden("fixtures/golden/text.txt", "line 1\nline 3\n")
+		}))
+		f := m.Failures()
+		return f[len(f)-1]
+	}
+
+	g.Eq(check(nil), "\n ⦗golden file⦘ fixtures/golden/text.txt ⦗mismatch⦘ \n\n"+
+		"`line 1\nline 3\n`\n\n ⦗not ==⦘ \n\n`line 1\nline 2\n`")
+
+	g.Eq(check(diff.ThemeNone), "\n ⦗golden file⦘ fixtures/golden/text.txt ⦗mismatch⦘ \n\n"+
+		"@@ diff chunk @@\n"+
+		"1 1   line 1\n"+
+		"2   - line 3\n"+
+		"  2 + line 2\n"+
+		"3 3   \n\n")
+}
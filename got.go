@@ -53,7 +53,27 @@ func New(t Testable) G {
 	return G{
 		t,
 		Assertions{Testable: t, ErrorHandler: eh},
-		Utils{t},
+		Utils{t, &randSource{}},
+	}
+}
+
+// Asserter is the interface that Must fails through, G implements it,
+// so does the Ctx of Each that embeds G.
+type Asserter interface {
+	Helper()
+	E(args ...interface{})
+}
+
+// Must returns a function that asserts err is nil and returns v.
+// Because methods can't be generic, the G is passed to the returned function, such as:
+//     n := got.Must(strconv.Atoi("10"))(g)
+// Inside a method of the Ctx of Each, pass the ctx itself, the failure will be reported to the subtest of the method:
+//     func (c Ctx) Parse() { n := got.Must(strconv.Atoi("10"))(c) }
+func Must[T any](v T, err error) func(g Asserter) T {
+	return func(g Asserter) T {
+		g.Helper()
+		g.E(err)
+		return v
 	}
 }
 
@@ -1,10 +1,28 @@
 package got_test
 
 import (
+	"errors"
+	"strconv"
 	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/got/lib/gop"
 )
 
 func TestSetup(t *testing.T) {
 	g := setup(t)
 	g.Eq(1, 1)
 }
+
+func TestMust(t *testing.T) {
+	g := setup(t)
+	g.Eq(got.Must(strconv.Atoi("10"))(g), 10)
+
+	m := &mock{t: t}
+	mg := got.New(m)
+	mg.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+	g.Panic(func() {
+		got.Must(0, errors.New("err"))(mg)
+	})
+	g.Has(m.msg, `s: "err"`)
+}
@@ -28,6 +28,24 @@ var ThemeNone = func(t Type) []gop.Style {
 	return []gop.Style{gop.None}
 }
 
+// Colorize formats the tokens like Format, but the colors are driven by a gop.Theme,
+// so that a message combines gop dumps and diffs can share one theme and gop.StripANSI strips both.
+// The inserted parts use the style of gop.DiffInsert, the deleted parts use gop.DiffDelete,
+// the chunk headers use gop.DiffChunk, the rest keep the default style.
+func Colorize(ts []*Token, theme gop.Theme) string {
+	return Format(ts, func(t Type) []gop.Style {
+		switch t {
+		case AddSymbol, AddWords:
+			return theme(gop.DiffInsert)
+		case DelSymbol, DelWords:
+			return theme(gop.DiffDelete)
+		case ChunkStart:
+			return theme(gop.DiffChunk)
+		}
+		return []gop.Style{gop.None}
+	})
+}
+
 // Diff x and y into a human readable string.
 func Diff(x, y string) string {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -50,6 +68,11 @@ func Format(ts []*Token, theme Theme) string {
 
 	for _, t := range ts {
 		s := t.Literal
+		if isANSI(s) {
+			// a style has zero width, there's no need to highlight it
+			out += s
+			continue
+		}
 		out += gop.Stylize(s, theme(t.Type))
 	}
 
@@ -119,6 +119,27 @@ func TestNoDifference(t *testing.T) {
 		"")
 }
 
+func TestIgnoreWS(t *testing.T) {
+	g := setup(t)
+
+	x := "a {\n  b:  1\n}\n"
+	y := "a {\n\tb: 1\n}\n"
+
+	lines := diff.Narrow(1, diff.ParseTokenLines(diff.TokenizeTextIgnoreWS(g.Context(), x, y)))
+	g.Eq(diff.Format(diff.SpreadTokenLines(lines), diff.ThemeNone), "")
+
+	ts := diff.TokenizeTextIgnoreWS(g.Context(), x, y+"c")
+	g.Eq(diff.Format(ts, diff.ThemeNone), ""+
+		"1 1   a {\n"+
+		"2 2     b:  1\n"+
+		"3 3   }\n"+
+		"4   - \n"+
+		"  4 + c\n")
+
+	g.Eq(diff.NewLineIgnoreWS([]byte(" a  b ")).String(), " a  b ")
+	g.Eq(diff.NewLineIgnoreWS([]byte(" a  b ")).Hash(), diff.NewLine([]byte("a b")).Hash())
+}
+
 func TestTwoLines(t *testing.T) {
 	g := setup(t)
 
@@ -183,3 +204,58 @@ func TestColor(t *testing.T) {
 
 `)
 }
+
+func TestColorize(t *testing.T) {
+	g := setup(t)
+
+	ts := diff.Tokenize(g.Context(), "abc", "axc")
+
+	out := diff.Colorize(ts, gop.ThemeDefault)
+	g.Eq(out, diff.Format(ts, diff.ThemeDefault))
+	g.Eq(gop.StripANSI(out), diff.Format(ts, diff.ThemeNone))
+
+	g.Eq(diff.Colorize(ts, gop.ThemeNone), diff.Format(ts, diff.ThemeNone))
+
+	underline := diff.Colorize(ts, func(t gop.Type) []gop.Style {
+		if t == gop.DiffInsert {
+			return []gop.Style{gop.Underline}
+		}
+		return []gop.Style{gop.None}
+	})
+	g.Eq(gop.VisualizeANSI(underline), `@@ diff chunk @@
+1   - abc
+<4>  1 +<24> a<4>x<24>c
+
+`)
+}
+
+func TestLineNumbers(t *testing.T) {
+	g := setup(t)
+
+	out := gop.StripANSI(diff.Diff(
+		strings.ReplaceAll("a b c d e f g h i j k", " ", "\n"),
+		strings.ReplaceAll("a b c x e f g h i j k y", " ", "\n"),
+	))
+
+	g.Eq(out, ""+
+		"@@ diff chunk @@\n"+
+		"03 03   c\n"+
+		"04    - d\n"+
+		"   04 + x\n"+
+		"05 05   e\n"+
+		"\n"+
+		"@@ diff chunk @@\n"+
+		"11 11   k\n"+
+		"   12 + y\n"+
+		"\n"+
+		"")
+}
+
+func TestStyledLine(t *testing.T) {
+	g := setup(t)
+
+	x, y := diff.TokenizeLine(g.Context(), gop.S("1", gop.Green), gop.S("2", gop.Yellow))
+
+	g.Eq(gop.VisualizeANSI(diff.Format(x, diff.ThemeDefault)), "<32><39><41>1<49><32><39>")
+	g.Eq(gop.VisualizeANSI(diff.Format(y, diff.ThemeDefault)), "<33><39><42>2<49><33><39>")
+}
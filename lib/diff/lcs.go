@@ -82,3 +82,98 @@ func (x Comparables) Histogram() map[string]int {
 	}
 	return his
 }
+
+// LCSLinear returns the same kind of result as LCS, but it uses Hirschberg's algorithm,
+// which only needs O(min(len(x), len(y))) extra memory for the dynamic programming table.
+// Use it when the inputs are large and mostly different.
+// The algorithm: https://en.wikipedia.org/wiki/Hirschberg%27s_algorithm
+func (x Comparables) LCSLinear(ctx context.Context, y Comparables) Comparables {
+	x = x.Reduce(y)
+	y = y.Reduce(x)
+
+	return hirschberg(ctx, x, y)
+}
+
+// hirschberg always splits the longer one of x and y, so that the rows of the table are built on the shorter one.
+// The items of the result are always from x.
+func hirschberg(ctx context.Context, x, y Comparables) Comparables {
+	if ctx.Err() != nil {
+		return Comparables{}
+	}
+
+	if l, r := x.Common(y); l+r > 0 {
+		lcs := append(Comparables{}, x[:l]...)
+		lcs = append(lcs, hirschberg(ctx, x[l:len(x)-r], y[l:len(y)-r])...)
+		return append(lcs, x[len(x)-r:]...)
+	}
+
+	if len(x) == 0 || len(y) == 0 {
+		return Comparables{}
+	}
+
+	if len(x) == 1 || len(y) == 1 {
+		for _, a := range x {
+			for _, b := range y {
+				if eq(a, b) {
+					return Comparables{a}
+				}
+			}
+		}
+		return Comparables{}
+	}
+
+	if len(x) >= len(y) {
+		mid := len(x) / 2
+		k := split(ctx, x[:mid], x[mid:], y)
+		return append(hirschberg(ctx, x[:mid], y[:k]), hirschberg(ctx, x[mid:], y[k:])...)
+	}
+
+	mid := len(y) / 2
+	k := split(ctx, y[:mid], y[mid:], x)
+	return append(hirschberg(ctx, x[:k], y[:mid]), hirschberg(ctx, x[k:], y[mid:])...)
+}
+
+// split returns the index of b where the lcs of a and b should be divided, a is the concatenation of a1 and a2.
+func split(ctx context.Context, a1, a2, b Comparables) int {
+	l1 := lcsLens(ctx, a1, b)
+	l2 := lcsLens(ctx, a2.reverse(), b.reverse())
+
+	k, best := 0, -1
+	for i := 0; i <= len(b); i++ {
+		if l := l1[i] + l2[len(b)-i]; l > best {
+			k, best = i, l
+		}
+	}
+	return k
+}
+
+// lcsLens returns the lengths of the lcs between x and each prefix of y, only two rows of the table are kept.
+func lcsLens(ctx context.Context, x, y Comparables) []int {
+	prev := make([]int, len(y)+1)
+	curr := make([]int, len(y)+1)
+
+	for _, a := range x {
+		if ctx.Err() != nil {
+			break
+		}
+
+		for j, b := range y {
+			if eq(a, b) {
+				curr[j+1] = prev[j] + 1
+			} else {
+				curr[j+1] = max(curr[j], prev[j+1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev
+}
+
+func (x Comparables) reverse() Comparables {
+	r := make(Comparables, len(x))
+	for i, c := range x {
+		r[len(x)-1-i] = c
+	}
+	return r
+}
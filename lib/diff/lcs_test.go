@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ysmood/got/lib/diff"
+	"github.com/ysmood/got/lib/gop"
 )
 
 func TestReduce(t *testing.T) {
@@ -86,6 +87,103 @@ func TestLCSString(t *testing.T) {
 	eq(string(x), string(y), "yx")
 }
 
+func TestLCSLinear(t *testing.T) {
+	g := setup(t)
+
+	eq := func(x, y, expected string) {
+		t.Helper()
+
+		lcs := diff.NewString(x).LCSLinear(context.Background(), diff.NewString(y))
+		g.Eq(lcs.String(), expected)
+	}
+
+	eq("", "", "")
+	eq("abc", "acb", "ac")
+	eq("abc", "acbc", "abc")
+	eq("abc", "xxx", "")
+	eq("ac", "bc", "c")
+	eq("gac", "agcat", "gc")
+	eq("agcat", "gac", "ga")
+	eq("abcbdab", "bdcaba", "bdab")
+	eq("bdcaba", "abcbdab", "bdab")
+	eq("abcd", "dcba", "c")
+	eq("caa", "bac", "a")
+	eq("cadc", "baadab", "ad")
+
+	x := bytes.Repeat([]byte("x"), 1000)
+	y := bytes.Repeat([]byte("y"), 1000)
+	x[len(x)/2] = byte('y')
+	y[len(y)/2] = byte('x')
+	eq(string(x), string(y), "yx")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g.Len(diff.NewString("abc").LCSLinear(ctx, diff.NewString("acb")), 0)
+
+	// cancelled while building the table
+	ctx = &countdownCtx{Context: context.Background(), n: 1}
+	g.Len(diff.NewString("abcd").LCSLinear(ctx, diff.NewString("dcba")), 0)
+}
+
+// countdownCtx will be cancelled after n calls of Err
+type countdownCtx struct {
+	context.Context
+	n int
+}
+
+func (c *countdownCtx) Err() error {
+	if c.n == 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func BenchmarkLCS(b *testing.B) {
+	x, y := benchmarkLCSInput()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		x.LCS(context.Background(), y)
+	}
+}
+
+func BenchmarkLCSLinear(b *testing.B) {
+	x, y := benchmarkLCSInput()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		x.LCSLinear(context.Background(), y)
+	}
+}
+
+func benchmarkLCSInput() (diff.Comparables, diff.Comparables) {
+	x := strings.Repeat("abcd", 50)
+	y := strings.Repeat("dcba", 50)
+	return diff.NewString(x), diff.NewString(y)
+}
+
+func TestString(t *testing.T) {
+	g := setup(t)
+	g.Len(diff.NewString("天a"), 2)
+	g.Eq(diff.NewString("天a").String(), "天a")
+
+	s := gop.S("天", gop.Red) + "a"
+	g.Len(diff.NewString(s), 4)
+	g.Eq(diff.NewString(s).String(), s)
+}
+
+func TestBytes(t *testing.T) {
+	g := setup(t)
+
+	x := []byte{0xff, 0x01, 0xfe, 0x02, 0x03}
+	y := []byte{0x01, 0xfe, 0xff, 0x03}
+
+	g.Len(diff.NewBytes(x), 5)
+	g.Eq(diff.NewBytes(x).String(), string(x))
+
+	lcs := diff.NewBytes(x).LCS(context.Background(), diff.NewBytes(y))
+	g.Eq([]byte(lcs.String()), []byte{0x01, 0xfe, 0x03})
+}
+
 func TestText(t *testing.T) {
 	g := setup(t)
 	g.Len(diff.NewText("a"), 1)
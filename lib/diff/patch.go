@@ -0,0 +1,141 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OpType of an edit
+type OpType int
+
+const (
+	// OpKeep type
+	OpKeep OpType = iota
+	// OpInsert type
+	OpInsert
+	// OpDelete type
+	OpDelete
+)
+
+var opSymbols = map[OpType]byte{OpKeep: ' ', OpInsert: '+', OpDelete: '-'}
+
+// Op is an edit that turns a part of the original string into the target string
+type Op struct {
+	Type OpType
+	Text string
+}
+
+// Ops returns the edits that turn x into y, the adjacent edits of the same type are merged into one.
+// It's based on the LCS of the runes of x and y, if ctx is done, the result will fallback to
+// deleting all of x and inserting all of y, it's still valid for Apply.
+func Ops(ctx context.Context, x, y string) []Op {
+	xs := NewString(x)
+	ys := NewString(y)
+
+	s := xs.LCS(ctx, ys)
+
+	ops := []Op{}
+	add := func(t OpType, c Comparable) {
+		if l := len(ops) - 1; l >= 0 && ops[l].Type == t {
+			ops[l].Text += c.String()
+			return
+		}
+		ops = append(ops, Op{t, c.String()})
+	}
+
+	for i, j, k := 0, 0, 0; i < len(xs) || j < len(ys); {
+		if i < len(xs) && (k == len(s) || neq(xs[i], s[k])) {
+			add(OpDelete, xs[i])
+			i++
+		} else if j < len(ys) && (k == len(s) || neq(ys[j], s[k])) {
+			add(OpInsert, ys[j])
+			j++
+		} else {
+			add(OpKeep, s[k])
+			i, j, k = i+1, j+1, k+1
+		}
+	}
+
+	return ops
+}
+
+// Apply the ops to the original string to get the target string, such as Apply(x, Ops(ctx, x, y)) == y.
+// It panics if the kept or deleted texts of the ops don't match the original.
+func Apply(original string, ops []Op) string {
+	out := strings.Builder{}
+	offset := 0
+
+	for _, op := range ops {
+		if op.Type == OpInsert {
+			out.WriteString(op.Text)
+			continue
+		}
+
+		if !strings.HasPrefix(original[offset:], op.Text) {
+			panic(fmt.Sprintf("diff: the op %q doesn't match the original at offset %d", Patch{op}.String(), offset))
+		}
+		offset += len(op.Text)
+
+		if op.Type == OpKeep {
+			out.WriteString(op.Text)
+		}
+	}
+
+	if offset != len(original) {
+		panic(fmt.Sprintf("diff: the ops only cover the first %d bytes of the original", offset))
+	}
+
+	return out.String()
+}
+
+// Patch is the serializable form of the ops. Each op is a line of the type symbol followed by the quoted text:
+//
+//     "abc"
+//    -"d"
+//    +"x\n"
+//
+// The symbol of OpKeep is a space, the symbol of OpInsert is "+", the symbol of OpDelete is "-".
+type Patch []Op
+
+// String interface
+func (p Patch) String() string {
+	lines := []string{}
+	for _, op := range p {
+		lines = append(lines, string(opSymbols[op.Type])+strconv.Quote(op.Text))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParsePatch parses the output of Patch.String
+func ParsePatch(s string) (Patch, error) {
+	p := Patch{}
+	if s == "" {
+		return p, nil
+	}
+
+	for i, line := range strings.Split(s, "\n") {
+		t, err := parseOpType(line)
+		if err == nil {
+			var text string
+			text, err = strconv.Unquote(line[1:])
+			p = append(p, Op{t, text})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("diff: invalid patch line %d %q: %w", i+1, line, err)
+		}
+	}
+
+	return p, nil
+}
+
+func parseOpType(line string) (OpType, error) {
+	for t, symbol := range opSymbols {
+		if len(line) > 0 && line[0] == symbol {
+			return t, nil
+		}
+	}
+	return 0, errors.New("unknown op symbol")
+}
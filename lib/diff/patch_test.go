@@ -0,0 +1,79 @@
+package diff_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/ysmood/got/lib/diff"
+)
+
+func TestOps(t *testing.T) {
+	g := setup(t)
+
+	g.Eq(diff.Ops(context.Background(), "abcd", "axcy"), []diff.Op{
+		{Type: diff.OpKeep, Text: "a"},
+		{Type: diff.OpDelete, Text: "b"},
+		{Type: diff.OpInsert, Text: "x"},
+		{Type: diff.OpKeep, Text: "c"},
+		{Type: diff.OpDelete, Text: "d"},
+		{Type: diff.OpInsert, Text: "y"},
+	})
+	g.Eq(diff.Ops(context.Background(), "", ""), []diff.Op{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g.Eq(diff.Ops(ctx, "abc", "abd"), []diff.Op{
+		{Type: diff.OpDelete, Text: "abc"},
+		{Type: diff.OpInsert, Text: "abd"},
+	})
+}
+
+func TestApply(t *testing.T) {
+	g := setup(t)
+
+	chars := []rune("ab天\n")
+	randStr := func() string {
+		s := make([]rune, rand.Intn(20))
+		for i := range s {
+			s[i] = chars[rand.Intn(len(chars))]
+		}
+		return string(s)
+	}
+
+	for i := 0; i < 300; i++ {
+		x, y := randStr(), randStr()
+		g.Desc("%q %q", x, y).Eq(diff.Apply(x, diff.Ops(context.Background(), x, y)), y)
+	}
+
+	apply := func(ops ...diff.Op) (err interface{}) {
+		defer func() { err = recover() }()
+		diff.Apply("abc", ops)
+		return
+	}
+	g.Eq(apply(diff.Op{Type: diff.OpKeep, Text: "a"}, diff.Op{Type: diff.OpDelete, Text: "c"}),
+		`diff: the op "-\"c\"" doesn't match the original at offset 1`)
+	g.Eq(apply(diff.Op{Type: diff.OpKeep, Text: "ab"}), "diff: the ops only cover the first 2 bytes of the original")
+}
+
+func TestPatch(t *testing.T) {
+	g := setup(t)
+
+	p := diff.Patch(diff.Ops(context.Background(), "a\nb", "a\nc\n"))
+	g.Eq(p.String(), " \"a\\n\"\n-\"b\"\n+\"c\\n\"")
+
+	parsed, err := diff.ParsePatch(p.String())
+	g.E(err)
+	g.Eq(parsed, p)
+	g.Eq(diff.Apply("a\nb", parsed), "a\nc\n")
+
+	parsed, err = diff.ParsePatch("")
+	g.E(err)
+	g.Len(parsed, 0)
+
+	_, err = diff.ParsePatch(" \"a\"\n*\"b\"")
+	g.Eq(err.Error(), `diff: invalid patch line 2 "*\"b\"": unknown op symbol`)
+
+	_, err = diff.ParsePatch("+b")
+	g.Eq(err.Error(), `diff: invalid patch line 1 "+b": invalid syntax`)
+}
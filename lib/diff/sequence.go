@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/md5"
+	"regexp"
+	"unicode/utf8"
 )
 
 // Comparables list
@@ -30,15 +32,53 @@ func (c Char) String() string {
 	return string(c)
 }
 
-// NewString from string
+// NewString from string. Each rune will be a Char, an ANSI escape sequence will be treated as a single Char,
+// so that the diff won't break the styles in s.
 func NewString(s string) Comparables {
-	cs := make([]Comparable, len(s))
-	for i, c := range s {
-		cs[i] = Char(c)
+	cs := []Comparable{}
+	for len(s) > 0 {
+		if loc := regANSI.FindStringIndex(s); loc != nil {
+			cs = append(cs, Char(s[:loc[1]]))
+			s = s[loc[1]:]
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s)
+		cs = append(cs, Char(r))
+		s = s[size:]
+	}
+	return cs
+}
+
+// Byte is a byte of binary data
+type Byte byte
+
+// Hash interface
+func (b Byte) Hash() string {
+	return string([]byte{byte(b)})
+}
+
+// String interface
+func (b Byte) String() string {
+	return string([]byte{byte(b)})
+}
+
+// NewBytes from binary data. Unlike NewString, each byte will be a Byte, so invalid UTF-8 data can be diffed.
+func NewBytes(b []byte) Comparables {
+	cs := make(Comparables, len(b))
+	for i, c := range b {
+		cs[i] = Byte(c)
 	}
 	return cs
 }
 
+var regANSI = regexp.MustCompile("^\u001B\\[\\d+m")
+
+func isANSI(s string) bool {
+	loc := regANSI.FindStringIndex(s)
+	return loc != nil && loc[1] == len(s)
+}
+
 // Line of a string for fast comparison.
 type Line struct {
 	str  string
@@ -65,16 +105,34 @@ func (c Line) String() string {
 	return c.str
 }
 
+// NewLineIgnoreWS is similar with NewLine, but the runs of whitespace in b won't matter for comparison,
+// the String will still return the original content.
+func NewLineIgnoreWS(b []byte) Line {
+	l := NewLine(bytes.Join(bytes.Fields(b), []byte(" ")))
+	l.str = string(b)
+	return l
+}
+
 // NewText from string. It will split the s via newlines.
 func NewText(s string) Comparables {
+	return newText(s, NewLine)
+}
+
+// NewTextIgnoreWS is similar with NewText, but each line is created by NewLineIgnoreWS,
+// such as to compare formatted code or config with different indentation.
+func NewTextIgnoreWS(s string) Comparables {
+	return newText(s, NewLineIgnoreWS)
+}
+
+func newText(s string, newLine func([]byte) Line) Comparables {
 	sc := bufio.NewScanner(bytes.NewBufferString(s))
 	cs := []Comparable{}
 	for sc.Scan() {
-		cs = append(cs, NewLine(sc.Bytes()))
+		cs = append(cs, newLine(sc.Bytes()))
 	}
 
 	if len(s) > 0 && s[len(s)-1] == '\n' {
-		cs = append(cs, NewLine([]byte{}))
+		cs = append(cs, newLine([]byte{}))
 	}
 
 	return cs
@@ -54,9 +54,17 @@ type Token struct {
 
 // TokenizeText text block a and b into diff tokens.
 func TokenizeText(ctx context.Context, x, y string) []*Token {
-	xls := NewText(x) // x lines
-	yls := NewText(y) // y lines
+	return tokenizeText(ctx, NewText(x), NewText(y))
+}
+
+// TokenizeTextIgnoreWS is similar with TokenizeText, but the runs of whitespace won't matter for comparison.
+// The same lines will be rendered as the ones in x.
+func TokenizeTextIgnoreWS(ctx context.Context, x, y string) []*Token {
+	return tokenizeText(ctx, NewTextIgnoreWS(x), NewTextIgnoreWS(y))
+}
 
+// xls and yls are the lines of x and y
+func tokenizeText(ctx context.Context, xls, yls Comparables) []*Token {
 	s := xls.LCS(ctx, yls)
 
 	ts := []*Token{}
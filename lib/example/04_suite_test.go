@@ -1,6 +1,7 @@
 package example_test
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
@@ -22,6 +23,12 @@ func (g SumSuite) Sum() {
 	g.Eq(example.Sum("1", "1"), "2")
 }
 
+func (g SumSuite) Atoi() {
+	// Use got.Must with the suite to unwrap the (value, error) results.
+	n := got.Must(strconv.Atoi(example.Sum("1", "1")))(g)
+	g.Eq(n, 2)
+}
+
 func TestSumAdvancedSuite(t *testing.T) {
 	// The got.Each can also accept a function to init the g for each test case.
 	got.Each(t, func(t *testing.T) SumAdvancedSuite {
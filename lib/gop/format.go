@@ -1,13 +1,17 @@
 package gop
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // Stdout is the default stdout for gop.P .
@@ -37,6 +41,12 @@ var ThemeDefault = func(t Type) []Style {
 		return []Style{Red}
 	case Error:
 		return []Style{Underline, Red}
+	case DiffInsert:
+		return []Style{BgGreen}
+	case DiffDelete:
+		return []Style{BgRed}
+	case DiffChunk:
+		return []Style{BgMagenta}
 	default:
 		return []Style{None}
 	}
@@ -49,7 +59,7 @@ var ThemeNone = func(t Type) []Style {
 
 // F is a shortcut for Format with color
 func F(v interface{}) string {
-	return Format(Tokenize(v), ThemeDefault)
+	return format(v, ThemeDefault)
 }
 
 // P pretty print the values
@@ -72,13 +82,137 @@ func P(values ...interface{}) error {
 
 // Plain is a shortcut for Format with plain color
 func Plain(v interface{}) string {
-	return Format(Tokenize(v), ThemeNone)
+	return format(v, ThemeNone)
+}
+
+var regAddr = regexp.MustCompile(`0x[0-9a-f]+`)
+var regPlaceholder = regexp.MustCompile("\x00(ptr\\d+)\x00")
+
+// FTemplate is similar with Plain, but the volatile addresses in the output, such as the ones of chan, func, and unsafe.Pointer,
+// will be replaced with placeholders like {{.ptr0}}, the same address will share the same placeholder.
+// The output is a text/template, executing it with the returned values will get the output of Plain,
+// so we can write stable assertions for the values that contain addresses.
+func FTemplate(v interface{}) (string, map[string]string) {
+	tz := NewTokenizer()
+	ts := tz.Tokenize(v)
+
+	values := map[string]string{}
+	names := map[string]string{}
+	for _, t := range ts {
+		// only the addresses rendered by the tokenizer, the user data, such as a struct tag, is kept as it is
+		if !tz.addrs[t] {
+			continue
+		}
+
+		t.Literal = regAddr.ReplaceAllStringFunc(t.Literal, func(addr string) string {
+			name, has := names[addr]
+			if !has {
+				name = fmt.Sprintf("ptr%d", len(names))
+				names[addr] = name
+				values[name] = addr
+			}
+			// use a sentinel, so that the placeholder won't be escaped below
+			return "\x00" + name + "\x00"
+		})
+	}
+
+	out := Format(ts, ThemeNone)
+	out = strings.ReplaceAll(out, "{{", `{{"{{"}}`)
+	out = regPlaceholder.ReplaceAllString(out, "{{.$1}}")
+
+	return out, values
+}
+
+// Short is similar with Plain, but it omits the type names and conversion wrappers for log-friendly output,
+// such as 100 instead of int8(100). The output is lossy and is not valid golang syntax.
+func Short(v interface{}) string {
+	tz := tokenizerPool.Get().(*Tokenizer)
+	defer tokenizerPool.Put(tz)
+	return Format(shorten(tz.Tokenize(v)), ThemeNone)
+}
+
+// JSONView is similar with Plain, but v is rendered as what json.Marshal produces in gop's layout,
+// such as the keys are the names from the json tags, the fields with json:"-" or the omitempty empty values are skipped,
+// and the keys keep the order of the marshaled JSON. The numbers are rendered as they are in the JSON,
+// so the large integers won't be rounded. If v can't be marshaled, it falls back to Plain with the error as a comment.
+func JSONView(v interface{}) string {
+	tz := tokenizerPool.Get().(*Tokenizer)
+	defer tokenizerPool.Put(tz)
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		ts := tz.Tokenize(v)
+		return Format(append([]*Token{tz.comment(err.Error())}, ts...), ThemeNone)
+	}
+
+	tz.Reset()
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return Format(tz.tokenizeJSONView(d), ThemeNone)
+}
+
+// shorten removes the type name tokens of conversions, composite literals, and type assertions
+func shorten(ts []*Token) []*Token {
+	is := func(i int, t Type) bool {
+		return i < len(ts) && ts[i].Type == t
+	}
+
+	out := []*Token{}
+	hidden := []bool{} // the stack of the parens, true if the paren is hidden
+	hideNext := false
+
+	for i := 0; i < len(ts); i++ {
+		t := ts[i]
+
+		switch t.Type {
+		case TypeName:
+			if is(i+1, ParenOpen) {
+				hideNext = true
+				continue
+			}
+			if is(i+1, Comment) || is(i+1, SliceOpen) || is(i+1, MapOpen) || is(i+1, StructOpen) {
+				continue
+			}
+
+		case ParenOpen:
+			// such as the (*int) in (*int)(nil)
+			if is(i+1, TypeName) && is(i+2, ParenClose) && is(i+3, ParenOpen) {
+				hideNext = true
+				i += 2
+				continue
+			}
+			hidden = append(hidden, hideNext)
+			hideNext = false
+			if hidden[len(hidden)-1] {
+				continue
+			}
+
+		case ParenClose:
+			h := hidden[len(hidden)-1]
+			hidden = hidden[:len(hidden)-1]
+			if h {
+				continue
+			}
+
+		case Dot:
+			// such as the .(*int) in gop.Ptr(1).(*int)
+			if is(i+1, ParenOpen) && is(i+2, TypeName) && is(i+3, ParenClose) {
+				i += 3
+				continue
+			}
+		}
+
+		out = append(out, t)
+	}
+
+	return out
 }
 
 // Format a list of tokens
 func Format(ts []*Token, theme Theme) string {
 	out := ""
 	depth := 0
+	inlineEnd := -1
 	for i, t := range ts {
 		if oneOf(t.Type, SliceOpen, MapOpen, StructOpen) {
 			depth++
@@ -89,6 +223,26 @@ func Format(ts []*Token, theme Theme) string {
 
 		styles := theme(t.Type)
 
+		if t.Type == StructOpen {
+			if end, ok := inlineStruct(ts, i); ok {
+				inlineEnd = end
+			}
+		}
+		if i <= inlineEnd {
+			switch t.Type {
+			case StructKey:
+			case Colon, InlineComma, Chan:
+				out += Stylize(t.Literal, styles) + " "
+			case Comma:
+				if ts[i+1].Type != StructClose {
+					out += Stylize(t.Literal, styles) + " "
+				}
+			default:
+				out += Stylize(t.Literal, styles)
+			}
+			continue
+		}
+
 		switch t.Type {
 		case SliceOpen, MapOpen, StructOpen:
 			out += Stylize(t.Literal, styles) + "\n"
@@ -110,6 +264,39 @@ func Format(ts []*Token, theme Theme) string {
 	return out
 }
 
+// inlineStruct returns the index of the StructClose of the struct that starts at ts[i],
+// ok is true if the struct can be rendered on one line within InlineStructWidth
+func inlineStruct(ts []*Token, i int) (end int, ok bool) {
+	if InlineStructWidth <= 0 {
+		return 0, false
+	}
+
+	width := len("{}")
+	for j := i + 1; ; j++ {
+		t := ts[j]
+		switch t.Type {
+		case SliceOpen, MapOpen, StructOpen:
+			return 0, false
+		case StructClose:
+			return j, width <= InlineStructWidth
+		case String:
+			s := readableStr(0, t.Literal)
+			if strings.Contains(s, "\n") {
+				return 0, false
+			}
+			width += utf8.RuneCountInString(s)
+		case Colon, InlineComma, Chan:
+			width += utf8.RuneCountInString(t.Literal) + 1
+		case Comma:
+			if ts[j+1].Type != StructClose {
+				width += len(", ")
+			}
+		default:
+			width += utf8.RuneCountInString(t.Literal)
+		}
+	}
+}
+
 func oneOf(t Type, list ...Type) bool {
 	for _, el := range list {
 		if t == el {
@@ -0,0 +1,34 @@
+//go:build go1.19
+
+package gop_test
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/got/lib/gop"
+)
+
+func TestAtomicTyped(t *testing.T) {
+	g := got.T(t)
+
+	var i atomic.Int64
+	i.Store(42)
+	var b atomic.Bool
+	b.Store(true)
+	var p atomic.Pointer[int]
+
+	g.Eq(gop.Plain(&i), "&/* atomic.Int64 */int64(42)")
+	g.Eq(gop.Plain(&b), "&/* atomic.Bool */true")
+	g.Eq(gop.Plain(&p), "&/* atomic.Pointer[int] */(*int)(nil)")
+
+	n := 1
+	p.Store(&n)
+	g.Eq(gop.Plain(&p), "&/* atomic.Pointer[int] */gop.Ptr(1).(*int)")
+
+	// the internal types of sync/atomic don't have the Load method
+	noCopy := reflect.TypeOf(atomic.Int64{}).Field(0).Type
+	g.Eq(gop.Plain(reflect.New(noCopy).Elem().Interface()), "atomic.noCopy{\n}")
+}
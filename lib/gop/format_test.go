@@ -3,11 +3,21 @@ package gop_test
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
 	"io/ioutil"
+	"math"
+	"net"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"text/template"
 	"time"
@@ -159,8 +169,1304 @@ func TestCircularSlice(t *testing.T) {
 		"            gop.Circular(0, 0).(gop.Arr),\n"+
 		"        },\n"+
 		"    },\n"+
-		"    gop.Circular(0, 0).(gop.Arr),\n"+
+		"    gop.Arr/* len=1 cap=1 *//* aliases prior slice */{\n"+
+		"        gop.Circular(1).(gop.Arr),\n"+
+		"    },\n"+
+		"}")
+}
+
+func TestSharedPointers(t *testing.T) {
+	g := got.T(t)
+	a := &A{Int: 1}
+
+	g.Eq(gop.Plain([]*A{a, a, {Int: 2}}), ""+
+		"[]*gop_test.A/* len=3 cap=3 */{\n"+
+		"    &gop_test.A/* len=2 */{\n"+
+		"        Int: 1,\n"+
+		"        B: (*gop_test.B)(nil),\n"+
+		"    },\n"+
+		"    gop.Circular(0).(*gop_test.A),\n"+
+		"    &gop_test.A/* len=2 */{\n"+
+		"        Int: 2,\n"+
+		"        B: (*gop_test.B)(nil),\n"+
+		"    },\n"+
+		"}")
+}
+
+func TestCircularPath(t *testing.T) {
+	g := got.T(t)
+	a := A{Int: 10}
+	b := B{"test", &a}
+	a.B = &b
+	m := map[string]interface{}{}
+	m["a b"] = []interface{}{1, m}
+
+	gop.CircularPath = gop.JSONPath
+	ref := gop.Plain(a)
+	key := gop.Plain(m)
+	gop.CircularPath = nil
+
+	g.Has(ref, "B: gop.Circular(/* $root.B */).(*gop_test.B),")
+	g.Has(key, "gop.Circular(/* $root */).(gop.Obj),")
+
+	g.Eq(gop.JSONPath([]interface{}{"A", "a b", 2, "B"}), `$root.A["a b"][2].B`)
+	g.Eq(gop.JSONPath(nil), "$root")
+}
+
+func TestSliceAlias(t *testing.T) {
+	g := got.T(t)
+
+	a := make([]int, 2, 3)
+	b := append(a, 1)
+
+	g.Eq(gop.Plain([][]int{a, b, {}, {}}), ""+
+		"[][]int/* len=4 cap=4 */{\n"+
+		"    []int/* len=2 cap=3 */{\n"+
+		"        0,\n"+
+		"        0,\n"+
+		"    },\n"+
+		"    []int/* len=3 cap=3 *//* aliases prior slice */{\n"+
+		"        0,\n"+
+		"        0,\n"+
+		"        1,\n"+
+		"    },\n"+
+		"    []int/* len=0 cap=0 */{\n"+
+		"    },\n"+
+		"    []int/* len=0 cap=0 */{\n"+
+		"    },\n"+
+		"}")
+
+	data := []byte("ab")
+	g.Eq(gop.Plain([][]byte{data, data[:1]}), ""+
+		"[][]uint8/* len=2 cap=2 */{\n"+
+		"    []byte(\"ab\"),\n"+
+		"    []byte(\"a\")/* aliases prior slice */,\n"+
+		"}")
+}
+
+func TestInterfaceElems(t *testing.T) {
+	g := got.T(t)
+
+	out := gop.Plain([]interface{}{int8(2), "x", 1, 1.5, uint(3), nil, []interface{}{float32(1)}})
+	g.Eq(out, ""+
+		"gop.Arr/* len=7 cap=7 */{\n"+
+		"    int8(2),\n"+
+		"    \"x\",\n"+
+		"    1,\n"+
+		"    float64(1.5),\n"+
+		"    uint(3),\n"+
+		"    nil,\n"+
+		"    gop.Arr/* len=1 cap=1 */{\n"+
+		"        float32(1),\n"+
+		"    },\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+
+	out = gop.Plain(map[string]interface{}{"a": int8(2), "b": "x", "c": map[int]interface{}{1: byte(1), 2: 2.0}})
+	g.Eq(out, ""+
+		"gop.Obj/* len=3 */{\n"+
+		"    \"a\": int8(2),\n"+
+		"    \"b\": \"x\",\n"+
+		"    \"c\": map[int]interface {}/* len=2 */{\n"+
+		"        1: byte(0x1),\n"+
+		"        2: float64(2),\n"+
+		"    },\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+}
+
+func TestPartialArray(t *testing.T) {
+	g := got.T(t)
+
+	out := gop.Plain([3]int{1, 2})
+	g.Eq(out, "[3]int{\n    1,\n    2,\n    0,\n}")
+	g.Nil(parser.ParseExpr(out))
+}
+
+func TestBytesAsHex(t *testing.T) {
+	g := got.T(t)
+
+	v := []interface{}{byte('a'), byte(0), byte('\''), byte('\\'), byte(0xe9)}
+
+	out := gop.Plain(v)
+	g.Eq(out, ""+
+		"gop.Arr/* len=5 cap=5 */{\n"+
+		"    byte('a'),\n"+
+		"    byte(0x0),\n"+
+		"    byte('\\''),\n"+
+		"    byte('\\\\'),\n"+
+		"    byte(0xe9),\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+
+	gop.BytesAsHex = true
+	out = gop.Plain(v)
+	gop.BytesAsHex = false
+
+	g.Eq(out, ""+
+		"gop.Arr/* len=5 cap=5 */{\n"+
+		"    byte(0x61),\n"+
+		"    byte(0x0),\n"+
+		"    byte(0x27),\n"+
+		"    byte(0x5c),\n"+
+		"    byte(0xe9),\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+}
+
+func TestCapHints(t *testing.T) {
+	g := got.T(t)
+
+	v := [][]int{make([]int, 1, 8), make([]int, 2, 4), {}}
+
+	gop.CapHints = true
+	out := gop.Plain(v)
+	gop.CapHints = false
+
+	g.Eq(out, ""+
+		"[][]int/* len=3 cap=3 */{\n"+
+		"    []int/* len=1 cap=8 over-allocated */{\n"+
+		"        0,\n"+
+		"    },\n"+
+		"    []int/* len=2 cap=4 */{\n"+
+		"        0,\n"+
+		"        0,\n"+
+		"    },\n"+
+		"    []int/* len=0 cap=0 */{\n"+
+		"    },\n"+
+		"}")
+}
+
+func TestLargeIntegers(t *testing.T) {
+	g := got.T(t)
+
+	check := func(v interface{}, expected string) {
+		t.Helper()
+
+		out := gop.Plain(v)
+		g.Eq(out, expected)
+
+		// the literal should be evaluated to the exact value
+		tv, err := types.Eval(token.NewFileSet(), nil, token.NoPos, out)
+		g.E(err)
+		g.Eq(tv.Value.ExactString(), fmt.Sprint(v))
+	}
+
+	check(uint64(math.MaxUint64), "uint64(18446744073709551615)")
+	check(int64(math.MinInt64), "int64(-9223372036854775808)")
+	check(int64(math.MaxInt64), "int64(9223372036854775807)")
+	check(math.MaxInt64, "9223372036854775807")
+}
+
+func TestRunes(t *testing.T) {
+	g := got.T(t)
+
+	out := gop.Plain([]rune("héllo"))
+	g.Eq(out, `[]rune("héllo")`)
+	g.Nil(parser.ParseExpr(out))
+
+	g.Eq(gop.Plain([]rune("long text with 天 and é")), "[]rune(`long text with 天 and é`)/* len=22 */")
+	g.Eq(gop.Plain([]rune{}), `[]rune("")`)
+
+	// the non-graphic runes and the arrays fall back to the rune literals
+	g.Eq(gop.Plain([]rune("a\n")), "[]int32/* len=2 cap=2 */{\n    'a',\n    int32(10),\n}")
+	g.Eq(gop.Plain([1]rune{'a'}), "[1]int32{\n    'a',\n}")
+
+	list := []rune("ab")
+	g.Eq(gop.Plain([][]rune{list, list}), "[][]int32/* len=2 cap=2 */{\n"+
+		"    []rune(\"ab\"),\n"+
+		"    []rune(\"ab\")/* aliases prior slice */,\n"+
+		"}")
+}
+
+func TestNilElements(t *testing.T) {
+	g := got.T(t)
+
+	v := []*int{nil, gop.Ptr(3).(*int)}
+	out := gop.Plain(v)
+	g.Eq(out, "[]*int/* len=2 cap=2 */{\n"+
+		"    (*int)(nil),\n"+
+		"    gop.Ptr(3).(*int),\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+
+	// the same literal as the output reconstructs an equal slice
+	g.Eq([]*int{(*int)(nil), gop.Ptr(3).(*int)}, v)
+
+	type item struct{ N int }
+	out = gop.Plain([]*item{nil, {1}})
+	g.Eq(out, "[]*gop_test.item/* len=2 cap=2 */{\n"+
+		"    (*gop_test.item)(nil),\n"+
+		"    &gop_test.item{\n"+
+		"        N: 1,\n"+
+		"    },\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+}
+
+func TestUintptr(t *testing.T) {
+	g := got.T(t)
+
+	n := 1
+	p := uintptr(unsafe.Pointer(&n))
+
+	out := gop.Plain(p)
+	g.Eq(out, fmt.Sprintf("uintptr(0x%x)", p))
+	g.Nil(parser.ParseExpr(out))
+}
+
+func TestComplex(t *testing.T) {
+	g := got.T(t)
+
+	check := func(v interface{}, expected string) {
+		t.Helper()
+
+		out := gop.Plain(v)
+		g.Eq(out, expected)
+		g.Nil(parser.ParseExpr(out))
+	}
+
+	check(complex64(1-2i), "complex64(1-2i)")
+	check(complex128(-1.5+2i), "-1.5+2i")
+	check(complex128(1e21-1e-7i), "1e+21-1e-07i")
+	check(complex(math.NaN(), -2), "complex(math.NaN(), -2)")
+	check(complex(1, math.Inf(-1)), "complex(1, math.Inf(-1))")
+	check(complex64(complex(math.Inf(1), 0)), "complex64(complex(math.Inf(1), 0))")
+}
+
+func TestTokenizer(t *testing.T) {
+	g := got.T(t)
+
+	v := map[string]interface{}{"a": []int{1, 2}, "b": &A{Int: 1}}
+	expected := gop.Format(gop.Tokenize(v), gop.ThemeNone)
+
+	tz := gop.NewTokenizer()
+	for i := 0; i < 3; i++ {
+		g.Eq(gop.Format(tz.Tokenize(v), gop.ThemeNone), expected)
+	}
+
+	large := make([]int, 1000)
+	g.Len(tz.Tokenize(large), len(gop.Tokenize(large)))
+}
+
+func BenchmarkTokenize(b *testing.B) {
+	v := map[string]interface{}{"a": []int{1, 2, 3}, "b": &A{Int: 1}, "c": "test"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		gop.Tokenize(v)
+	}
+}
+
+func BenchmarkTokenizer(b *testing.B) {
+	v := map[string]interface{}{"a": []int{1, 2, 3}, "b": &A{Int: 1}, "c": "test"}
+	tz := gop.NewTokenizer()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tz.Tokenize(v)
+	}
+}
+
+func TestPointerKeys(t *testing.T) {
+	g := got.T(t)
+
+	for i := 0; i < 10; i++ {
+		a, b, c, d := 3, 2, 1, 1
+		v := map[*int]string{&a: "a", &b: "b", &c: "c", &d: "d"}
+
+		out := gop.Plain(v)
+		g.Eq(out, ""+
+			"map[*int]string/* len=4 */{\n"+
+			"    gop.Ptr(1).(*int): \"c\",\n"+
+			"    gop.Ptr(1).(*int): \"d\",\n"+
+			"    gop.Ptr(2).(*int): \"b\",\n"+
+			"    gop.Ptr(3).(*int): \"a\",\n"+
+			"}")
+		g.Nil(parser.ParseExpr(out))
+	}
+}
+
+func TestCircularPointerKeys(t *testing.T) {
+	g := got.T(t)
+
+	type node struct{ M map[*node]int }
+
+	n := &node{}
+	n.M = map[*node]int{n: 1, {}: 2}
+
+	out := gop.Plain(n)
+	g.Eq(out, ""+
+		"&gop_test.node{\n"+
+		"    M: map[*gop_test.node]int/* len=2 */{\n"+
+		"        &gop_test.node{\n"+
+		"            M: map[*gop_test.node]int{\n"+
+		"            },\n"+
+		"        }: 2,\n"+
+		"        gop.Circular().(*gop_test.node): 1,\n"+
+		"    },\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+}
+
+type badText struct{}
+
+func (badText) MarshalText() ([]byte, error) { return nil, errors.New("err") }
+
+func (*badText) UnmarshalText([]byte) error { return nil }
+
+type unmarshalOnly struct{}
+
+func (*unmarshalOnly) UnmarshalText([]byte) error { return nil }
+
+func TestStructKeys(t *testing.T) {
+	g := got.T(t)
+
+	type point struct {
+		X, Y int
+		P    *int
+	}
+
+	a, b := 1, 1
+	for i := 0; i < 100; i++ {
+		g.Eq(gop.Plain(map[point]string{{1, 2, nil}: "a", {0, 0, nil}: "b", {0, 0, &a}: "c", {0, 0, &b}: "d"}), ""+
+			"map[gop_test.point]string/* len=4 */{\n"+
+			"    gop_test.point/* len=3 */{\n"+
+			"        X: 0,\n"+
+			"        Y: 0,\n"+
+			"        P: (*int)(nil),\n"+
+			"    }: \"b\",\n"+
+			"    gop_test.point/* len=3 */{\n"+
+			"        X: 0,\n"+
+			"        Y: 0,\n"+
+			"        P: gop.Ptr(1).(*int),\n"+
+			"    }: \"c\",\n"+
+			"    gop_test.point/* len=3 */{\n"+
+			"        X: 0,\n"+
+			"        Y: 0,\n"+
+			"        P: gop.Ptr(1).(*int),\n"+
+			"    }: \"d\",\n"+
+			"    gop_test.point/* len=3 */{\n"+
+			"        X: 1,\n"+
+			"        Y: 2,\n"+
+			"        P: (*int)(nil),\n"+
+			"    }: \"a\",\n"+
+			"}")
+	}
+}
+
+type orderedMap map[string]int
+
+func (orderedMap) Keys() []interface{} {
+	return []interface{}{"c", nil, 1, "a", "c", "x"}
+}
+
+type orderedAnyMap map[interface{}]int
+
+func (orderedAnyMap) Keys() []interface{} {
+	return []interface{}{"z", 1, "a"}
+}
+
+func TestOrderedKeys(t *testing.T) {
+	g := got.T(t)
+
+	g.Eq(gop.Plain(orderedMap{"a": 1, "b": 2, "c": 3, "d": 4}), "gop_test.orderedMap/* len=4 */{\n"+
+		"    \"c\": 3,\n"+
+		"    \"a\": 1,\n"+
+		"    \"b\": 2,\n"+
+		"    \"d\": 4,\n"+
+		"}")
+
+	g.Eq(gop.Plain(orderedAnyMap{"a": 1, "m": 2, "z": 3}), "gop_test.orderedAnyMap/* len=3 */{\n"+
+		"    \"z\": 3,\n"+
+		"    \"a\": 1,\n"+
+		"    \"m\": 2,\n"+
+		"}")
+}
+
+func TestTextMarshaler(t *testing.T) {
+	g := got.T(t)
+
+	ip := net.ParseIP("127.0.0.1")
+	g.Eq(gop.Plain(badText{}), "gop_test.badText{\n}")
+
+	gop.UseTextMarshaler = true
+	defer func() { gop.UseTextMarshaler = false }()
+
+	out := gop.Plain(ip)
+	g.Eq(out, "gop.Text(\"127.0.0.1\", (*net.IP)(nil)).(net.IP)")
+	g.Nil(parser.ParseExpr(out))
+	g.Eq(gop.Text("127.0.0.1", (*net.IP)(nil)).(net.IP), ip)
+
+	g.Eq(gop.Plain(&ip), "gop.Ptr(gop.Text(\"127.0.0.1\", (*net.IP)(nil)).(net.IP)).(*net.IP)")
+	g.Eq(gop.Plain(badText{}), "gop_test.badText{\n}")
+	g.Eq(gop.Plain(1), "1")
+	g.Eq(gop.Plain(unmarshalOnly{}), "gop_test.unmarshalOnly{\n}")
+}
+
+type apiErr struct {
+	Code int
+}
+
+func (e *apiErr) Error() string { return fmt.Sprintf("code: %d", e.Code) }
+
+type valErr string
+
+func (e valErr) Error() string { return string(e) }
+
+func TestErrorFields(t *testing.T) {
+	g := got.T(t)
+
+	type res struct {
+		Err   error
+		Other error
+		Val   interface{}
+	}
+
+	v := res{Err: &apiErr{404}, Other: valErr("x"), Val: &apiErr{500}}
+
+	g.Eq(gop.Plain(v), `gop_test.res/* len=3 */{
+    Err: &gop_test.apiErr{
+        Code: 404,
+    },
+    Other: "x",
+    Val: &gop_test.apiErr{
+        Code: 500,
+    },
+}`)
+
+	gop.ErrorFields = true
+	defer func() { gop.ErrorFields = false }()
+
+	out := gop.Plain(v)
+	g.Eq(out, `gop_test.res/* len=3 */{
+    Err: gop.Err("code: 404", (*gop_test.apiErr)(nil)),
+    Other: gop.Err("x", (*gop_test.valErr)(nil)),
+    Val: &gop_test.apiErr{
+        Code: 500,
+    },
+}`)
+	g.Nil(parser.ParseExpr(out))
+	g.Eq(gop.Err("code: 404", (*apiErr)(nil)).Error(), "code: 404")
+	g.Eq(gop.Plain(res{}), "gop_test.res/* len=3 */{\n    Err: nil,\n    Other: nil,\n    Val: nil,\n}")
+}
+
+type panicText struct{}
+
+func (panicText) MarshalText() ([]byte, error) { panic("boom") }
+
+func (*panicText) UnmarshalText([]byte) error { return nil }
+
+func TestUnreadable(t *testing.T) {
+	g := got.T(t)
+
+	type res struct {
+		Err error
+	}
+
+	gop.ErrorFields = true
+	gop.UseTextMarshaler = true
+	out := gop.Plain([]interface{}{res{(*apiErr)(nil)}, panicText{}, unsafe.Pointer(nil)})
+	gop.ErrorFields = false
+	gop.UseTextMarshaler = false
+
+	g.Eq(out, ""+
+		"gop.Arr/* len=3 cap=3 */{\n"+
+		"    gop_test.res{\n"+
+		"        Err: nil/* unreadable */,\n"+
+		"    },\n"+
+		"    nil/* unreadable */,\n"+
+		"    unsafe.Pointer(uintptr(0x0)),\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+}
+
+type deps struct {
+	io.Reader
+	Name string
+}
+
+func TestEmbeddedInterfaceTypes(t *testing.T) {
+	g := got.T(t)
+
+	v := []deps{{}, {strings.NewReader(""), "x"}}
+
+	gop.EmbeddedInterfaceTypes = true
+	out := gop.Plain(v)
+	gop.EmbeddedInterfaceTypes = false
+
+	g.Eq(out, ""+
+		"[]gop_test.deps/* len=2 cap=2 */{\n"+
+		"    gop_test.deps/* len=2 */{\n"+
+		"        Reader: /* io.Reader */nil,\n"+
+		"        Name: \"\",\n"+
+		"    },\n"+
+		"    gop_test.deps/* len=2 */{\n"+
+		"        Reader: /* io.Reader */&strings.Reader/* len=3 */{\n"+
+		"            s: \"\",\n"+
+		"            i: int64(0),\n"+
+		"            prevRune: -1,\n"+
+		"        },\n"+
+		"        Name: \"x\",\n"+
+		"    },\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+}
+
+func TestInlineStructWidth(t *testing.T) {
+	g := got.T(t)
+
+	type point struct {
+		X, Y int
+	}
+	type shape struct {
+		Name   string
+		Points []point
+		Ch     chan int
+		Line   [2]point
+	}
+
+	v := shape{"line", []point{{1, 2}}, nil, [2]point{{3, 4}, {5, 6}}}
+
+	gop.InlineStructWidth = 16
+	outs := []string{
+		gop.Plain(v),
+		gop.Plain(point{100, 200}),
+		gop.Plain(point{1000, 2000}),
+		gop.Plain(struct{ S string }{"a\nb"}),
+		gop.Plain(struct{}{}),
+	}
+	gop.InlineStructWidth = 0
+
+	g.Eq(outs, []string{""+
+		"gop_test.shape/* len=4 */{\n"+
+		"    Name: \"line\",\n"+
+		"    Points: []gop_test.point/* len=1 cap=1 */{\n"+
+		"        gop_test.point/* len=2 */{X: 1, Y: 2},\n"+
+		"    },\n"+
+		"    Ch: make(chan int)/* 0x0 */,\n"+
+		"    Line: [2]gop_test.point{\n"+
+		"        gop_test.point/* len=2 */{X: 3, Y: 4},\n"+
+		"        gop_test.point/* len=2 */{X: 5, Y: 6},\n"+
+		"    },\n"+
+		"}",
+		"gop_test.point/* len=2 */{X: 100, Y: 200}",
+		"gop_test.point/* len=2 */{\n    X: 1000,\n    Y: 2000,\n}",
+		"struct { S string }{\n    S: `a\nb`,\n}",
+		"struct {}{}",
+	})
+	for _, out := range outs {
+		g.Nil(parser.ParseExpr(out))
+	}
+}
+
+func TestMapKeyRange(t *testing.T) {
+	g := got.T(t)
+
+	gop.MapKeyRange = 3
+	ints := gop.Plain(map[int]bool{3: true, -2: false, 10: true})
+	uints := gop.Plain(map[uint8]bool{3: true, 2: false, 1: true})
+	floats := gop.Plain(map[float32]bool{1.5: true, -0.25: false, 2: true})
+	strs := gop.Plain(map[string]int{"b": 1, "a": 2, "c": 3})
+	short := gop.Plain(map[int]bool{1: true, 2: true})
+	unordered := gop.Plain(map[complex64]int{1: 1, 2i: 2, 3: 3})
+	gop.MapKeyRange = 0
+	off := gop.Plain(map[int]bool{3: true, -2: false, 10: true})
+
+	g.Has(ints, "map[int]bool/* len=3 keys=-2..10 */{")
+	g.Has(uints, "map[uint8]bool/* len=3 keys=1..3 */{")
+	g.Has(floats, "map[float32]bool/* len=3 keys=-0.25..2 */{")
+	g.Has(strs, `map[string]int/* len=3 keys="a".."c" */{`)
+	g.Has(short, "map[int]bool/* len=2 */{")
+	g.Has(unordered, "map[complex64]int/* len=3 */{")
+	g.Has(off, "map[int]bool/* len=3 */{")
+	g.Nil(parser.ParseExpr(strs))
+}
+
+type implementer struct{}
+
+func (implementer) Error() string { return "" }
+
+func (implementer) String() string { return "" }
+
+func (*implementer) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestShowImplements(t *testing.T) {
+	g := got.T(t)
+
+	gop.ShowImplements = true
+	gop.ShowKind = true
+	val := gop.Plain(implementer{})
+	gop.ShowKind = false
+	ptr := gop.Plain(&implementer{})
+	nested := gop.Plain([]interface{}{implementer{}})
+	none := gop.Plain(1)
+	n := gop.Plain(nil)
+	gop.ShowImplements = false
+
+	g.Eq(val, "/* kind=struct *//* implements: error, fmt.Stringer *//* *gop_test.implementer implements: io.Writer */"+
+		"gop_test.implementer{\n}")
+	g.Eq(ptr, "/* implements: error, fmt.Stringer, io.Writer */&gop_test.implementer{\n}")
+	g.Eq(nested, "gop.Arr/* len=1 cap=1 */{\n    gop_test.implementer{\n    },\n}")
+	g.Eq(none, "1")
+	g.Eq(n, "nil")
+	g.Nil(parser.ParseExpr(val))
+}
+
+func TestShowKind(t *testing.T) {
+	g := got.T(t)
+
+	gop.ShowKind = true
+	list := gop.Plain([]int{1})
+	arr := gop.Plain([1]int{1})
+	m := gop.Plain(map[int]int{})
+	n := gop.Plain(nil)
+	nested := gop.Plain([]interface{}{[]int{}})
+	gop.ShowKind = false
+
+	g.Eq(list, "/* kind=slice */[]int/* len=1 cap=1 */{\n    1,\n}")
+	g.Eq(arr, "/* kind=array */[1]int{\n    1,\n}")
+	g.Eq(m, "/* kind=map */map[int]int{\n}")
+	g.Eq(n, "nil")
+	g.Eq(nested, "/* kind=slice */gop.Arr/* len=1 cap=1 */{\n    []int/* len=0 cap=0 */{\n    },\n}")
+	g.Nil(parser.ParseExpr(list))
+}
+
+func TestDrainChan(t *testing.T) {
+	g := got.T(t)
+
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	empty := make(chan string, 1)
+
+	gop.DrainChan = true
+	out, _ := gop.FTemplate([]interface{}{ch, (<-chan int)(ch), empty})
+	gop.DrainChan = false
+
+	g.Eq(out, ""+
+		"gop.Arr/* len=3 cap=3 */{\n"+
+		"    make(chan int, 3)/* {{.ptr0}} *//* buffered: [1, 2] */,\n"+
+		"    make(chan int, 3)/* {{.ptr0}} */,\n"+
+		"    make(chan string, 1)/* {{.ptr1}} */,\n"+
 		"}")
+
+	g.Len(ch, 2)
+	g.Eq(<-ch, 1)
+	g.Eq(<-ch, 2)
+}
+
+func TestRepeatRuns(t *testing.T) {
+	g := got.T(t)
+
+	gop.RepeatRuns = 3
+	outs := []string{
+		gop.Plain([]int{1, 0, 0, 0, 0, 2, 2, 3, 3, 3}),
+		gop.Plain([2][]string{{"a", "a", "a"}, {"a"}}),
+	}
+	gop.RepeatRuns = 0
+
+	g.Eq(outs, []string{""+
+		"[]int/* len=10 cap=10 */{\n"+
+		"    1,\n"+
+		"    gop.Repeat(0, 4),\n"+
+		"    2,\n"+
+		"    2,\n"+
+		"    gop.Repeat(3, 3),\n"+
+		"}", ""+
+		"[2][]string{\n"+
+		"    []string/* len=3 cap=3 */{\n"+
+		"        gop.Repeat(\"a\", 3),\n"+
+		"    },\n"+
+		"    []string/* len=1 cap=1 */{\n"+
+		"        \"a\",\n"+
+		"    },\n"+
+		"}"})
+
+	for _, out := range outs {
+		g.Nil(parser.ParseExpr(out))
+	}
+	g.Eq(gop.Repeat("a", 3), []interface{}{"a", "a", "a"})
+
+	g.Eq(gop.Plain([]int{0, 0, 0}), "[]int/* len=3 cap=3 */{\n    0,\n    0,\n    0,\n}")
+}
+
+func TestReadPrivate(t *testing.T) {
+	g := got.T(t)
+
+	type row struct {
+		Name string
+		tag  string
+		at   time.Time
+	}
+	type event struct {
+		At time.Time
+		r  row
+	}
+
+	v := event{time.Now(), row{"a", "b", time.Time{}}}
+
+	gop.ReadPrivate = false
+	out := gop.Plain(v)
+	tbl := gop.Table([]row{{"a", "b", time.Time{}}})
+	gop.ReadPrivate = true
+
+	g.Eq(out, ""+
+		"gop_test.event/* len=2 */{\n"+
+		"    At: gop.Time(`"+v.At.Format(time.RFC3339Nano)+"`, 0),\n"+
+		"    r: /* unexported */nil,\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+	g.Eq(tbl, ""+
+		"Name | tag              | at\n"+
+		"-----|------------------|-----------------\n"+
+		"\"a\"  | /* unexported */ | /* unexported */")
+
+	g.Has(gop.Plain(v), `tag: "b"`)
+}
+
+func TestSync(t *testing.T) {
+	g := got.T(t)
+
+	type cache struct {
+		sync.Mutex
+		rw   sync.RWMutex
+		read sync.RWMutex
+		once sync.Once
+		init sync.Once
+		wg   *sync.WaitGroup
+	}
+
+	c := &cache{wg: &sync.WaitGroup{}}
+	c.Lock()
+	c.rw.Lock()
+	c.read.RLock()
+	c.init.Do(func() {})
+
+	out := gop.Plain(c)
+	g.Eq(out, ""+
+		"&gop_test.cache/* len=6 */{\n"+
+		"    Mutex: sync.Mutex{}/* locked */,\n"+
+		"    rw: sync.RWMutex{}/* locked */,\n"+
+		"    read: sync.RWMutex{}/* read-locked */,\n"+
+		"    once: sync.Once{}/* not done */,\n"+
+		"    init: sync.Once{}/* done */,\n"+
+		"    wg: &sync.WaitGroup{},\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+
+	c.Unlock()
+	g.Eq(gop.Plain(sync.RWMutex{}), "sync.RWMutex{}/* unlocked */")
+	g.Eq(gop.Plain(&c.Mutex), "&sync.Mutex{}/* unlocked */")
+	g.False(c.read.TryLock())
+
+	// the Do that is still running
+	running := ""
+	c.once.Do(func() { running = gop.Plain(&c.once) })
+	g.Eq(running, "&sync.Once{}/* not done */")
+	g.Eq(gop.Plain(&c.once), "&sync.Once{}/* done */")
+}
+
+func TestAtomic(t *testing.T) {
+	g := got.T(t)
+
+	type config struct {
+		v    atomic.Value
+		list *atomic.Value
+	}
+
+	c := &config{list: &atomic.Value{}}
+	c.v.Store("ok")
+	c.list.Store([]int{1})
+
+	out := gop.Plain(c)
+	g.Eq(out, ""+
+		"&gop_test.config/* len=2 */{\n"+
+		"    v: /* atomic.Value */\"ok\",\n"+
+		"    list: &/* atomic.Value */[]int/* len=1 cap=1 */{\n"+
+		"        1,\n"+
+		"    },\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+
+	g.Eq(gop.Plain(atomic.Value{}), "/* atomic.Value */nil")
+}
+
+func TestShowTags(t *testing.T) {
+	g := got.T(t)
+
+	type data struct {
+		Name string `json:"name"`
+		Age  int
+		Bad  int `x:"*/"`
+		Ctrl int "x:\"\t\x7f\""
+	}
+
+	v := data{"x", 1, 2, 3}
+	g.Eq(gop.Plain(v), "gop_test.data/* len=4 */{\n    Name: \"x\",\n    Age: 1,\n    Bad: 2,\n    Ctrl: 3,\n}")
+
+	gop.ShowTags = true
+	defer func() { gop.ShowTags = false }()
+
+	out := gop.Plain(v)
+	g.Eq(out, "gop_test.data/* len=4 */{\n"+
+		"    Name/* json:\"name\" */: \"x\",\n"+
+		"    Age: 1,\n"+
+		"    Bad/* x:\"*\\/\" */: 2,\n"+
+		"    Ctrl/* x:\"\\x09\\x7f\" */: 3,\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+}
+
+func TestStringPreview(t *testing.T) {
+	g := got.T(t)
+
+	gop.StringPreview = 6
+	outs := []string{gop.Plain("abcdef"), gop.Plain("abc12345def"), gop.Plain("天12345地"), gop.Plain("天地玄黄宇宙洪荒")}
+	gop.StringPreview = 0
+
+	g.Eq(outs, []string{`"abcdef"`, `"abc...def"/* truncated len=11 */`, `"天12...45地"/* truncated len=7 */`,
+		"`天地玄...宙洪荒`/* truncated len=8 */"})
+}
+
+func TestMapKeyPreview(t *testing.T) {
+	g := got.T(t)
+
+	m := map[string]int{"b-long-key-0123456789": 1, "a-long-key-9876543210": 2, "c": 3}
+	obj := gop.Obj{"a-long-key-9876543210": 1}
+
+	g.Eq(gop.Plain(m), ""+
+		"map[string]int/* len=3 */{\n"+
+		"    `a-long-key-9876543210`/* len=21 */: 2,\n"+
+		"    `b-long-key-0123456789`/* len=21 */: 1,\n"+
+		"    \"c\": 3,\n"+
+		"}")
+
+	type key string
+	named := map[key]int{"a-long-key-9876543210": 1}
+
+	gop.MapKeyPreview = 6
+	outs := []string{gop.Plain(m), gop.Plain(obj), gop.Plain(named)}
+	gop.MapKeyPreview = 0
+
+	g.Eq(outs, []string{"" +
+		"map[string]int/* len=3 */{\n" +
+		"    \"a-l...210\"/* truncated len=21 */: 2,\n" +
+		"    \"b-l...789\"/* truncated len=21 */: 1,\n" +
+		"    \"c\": 3,\n" +
+		"}", "" +
+		"gop.Obj{\n" +
+		"    \"a-l...210\"/* truncated len=21 */: 1,\n" +
+		"}", "" +
+		"map[gop_test.key]int{\n" +
+		"    gop_test.key(\"a-l...210\")/* truncated len=21 */: 1,\n" +
+		"}"})
+	g.Nil(parser.ParseExpr(outs[2]))
+}
+
+func TestNoTruncate(t *testing.T) {
+	g := got.T(t)
+
+	tz := gop.NewTokenizer()
+	tz.NoTruncate = true
+
+	gop.StringPreview = 4
+	gop.MapKeyPreview = 4
+	out := gop.Format(tz.Tokenize(map[string]string{"abc123def": "abc456def"}), gop.ThemeNone)
+	gop.StringPreview = 0
+	gop.MapKeyPreview = 0
+
+	g.Eq(out, "map[string]string{\n    \"abc123def\": \"abc456def\",\n}")
+}
+
+type status int
+
+const (
+	statusIdle status = iota
+	statusActive
+	statusDone
+)
+
+type level uint8
+
+func TestRegisterEnum(t *testing.T) {
+	g := got.T(t)
+
+	gop.RegisterEnum(map[status]string{
+		statusIdle:   "statusIdle",
+		statusActive: "statusActive",
+	})
+	gop.RegisterEnum(map[level]string{1: "levelLow"})
+
+	g.Eq(gop.Plain([]status{statusIdle, statusActive, statusDone}), "[]gop_test.status/* len=3 cap=3 */{\n"+
+		"    statusIdle/* 0 */,\n"+
+		"    statusActive/* 1 */,\n"+
+		"    2,\n"+
+		"}")
+	g.Eq(gop.Plain(level(1)), "levelLow/* 1 */")
+	g.Eq(gop.Plain(1), "1")
+}
+
+type color uint8
+
+const (
+	colorRed color = iota + 1
+	colorGreen
+)
+
+func (c color) String() string {
+	switch c {
+	case colorRed:
+		return "Red"
+	case colorGreen:
+		return "Green"
+	case 3:
+		return "3"
+	case 4:
+		panic("boom")
+	case 5:
+		return ""
+	}
+	return fmt.Sprintf("color(%d)", c)
+}
+
+type weight int
+
+func (w weight) String() string { return "1.5kg" }
+
+func TestStringer(t *testing.T) {
+	g := got.T(t)
+
+	g.Eq(gop.Plain([]color{colorRed, colorGreen, 3, 4, 5, 6}), "[]gop_test.color/* len=6 cap=6 */{\n"+
+		"    gop_test.color(1)/* Red */,\n"+
+		"    gop_test.color(2)/* Green */,\n"+
+		"    color(3),\n"+
+		"    color(4),\n"+
+		"    color(5),\n"+
+		"    color(6),\n"+
+		"}")
+	g.Eq(gop.Plain(weight(1)), "gop_test.weight(1)/* 1.5kg */")
+	g.Eq(gop.Plain(time.March), "time.Month(3)/* March */")
+}
+
+func TestFTemplate(t *testing.T) {
+	g := got.T(t)
+
+	ch := make(chan int)
+	ref := 1
+	v := []interface{}{ch, ch, unsafe.Pointer(&ref), "{{.ptr0}}"}
+
+	out, values := gop.FTemplate(v)
+	g.Eq(out, "gop.Arr/* len=4 cap=4 */{\n"+
+		"    make(chan int)/* {{.ptr0}} */,\n"+
+		"    make(chan int)/* {{.ptr0}} */,\n"+
+		"    unsafe.Pointer(uintptr({{.ptr1}})),\n"+
+		"    \"{{\"{{\"}}.ptr0}}\",\n"+
+		"}")
+	g.Eq(values, map[string]string{
+		"ptr0": fmt.Sprintf("0x%x", reflect.ValueOf(ch).Pointer()),
+		"ptr1": fmt.Sprintf("%v", &ref),
+	})
+
+	expected := bytes.NewBuffer(nil)
+	g.E(template.Must(template.New("").Parse(out)).Execute(expected, values))
+	g.Eq(expected.String(), gop.Plain(v))
+}
+
+func TestFTemplateUserData(t *testing.T) {
+	g := got.T(t)
+
+	type data struct {
+		ID int `id:"0xbeef"`
+	}
+
+	gop.ShowTags = true
+	out, values := gop.FTemplate(data{})
+	gop.ShowTags = false
+
+	g.Eq(out, "gop_test.data{\n    ID/* id:\"0xbeef\" */: 0,\n}")
+	g.Len(values, 0)
+}
+
+func TestRune(t *testing.T) {
+	g := got.T(t)
+
+	g.Eq(gop.Plain('a'), `'a'`)
+	g.Eq(gop.Plain('\''), `'\''`)
+	g.Eq(gop.Plain('\\'), `'\\'`)
+	g.Eq(gop.Plain('\u0301'), `'\u0301'`)
+	g.Eq(gop.Plain('\u20dd'), `'\u20dd'`)
+	g.Eq(gop.Plain('\u00a0'), `'\u00a0'`)
+	g.Eq(gop.Plain('\U000e0100'), `'\U000e0100'`)
+	g.Eq(gop.Plain('\x00'), `int32(0)`)
+
+	for _, r := range []rune{'\'', '\\', '\u0301', '\U000e0100'} {
+		out := gop.Plain(r)
+		g.Nil(parser.ParseExpr(out))
+	}
+}
+
+func TestShowInterfaceType(t *testing.T) {
+	g := got.T(t)
+
+	type data struct {
+		Any interface{}
+		Err error
+		Nil interface{}
+	}
+
+	v := data{1, &apiErr{1}, nil}
+
+	gop.ShowInterfaceType = true
+	out := gop.Plain(v)
+	gop.ShowInterfaceType = false
+
+	g.Eq(out, "gop_test.data/* len=3 */{\n"+
+		"    Any: interface {}(1),\n"+
+		"    Err: error(&gop_test.apiErr{\n"+
+		"        Code: 1,\n"+
+		"    }),\n"+
+		"    Nil: nil,\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+	g.Eq(gop.Plain(v.Any), "1")
+}
+
+func TestRedact(t *testing.T) {
+	g := got.T(t)
+
+	type data struct {
+		ID  int
+		Err error
+		Sub map[string]interface{}
+	}
+
+	tz := gop.NewTokenizer()
+	tz.Redact = gop.RedactPaths("ID", "Err", "Sub.a")
+
+	gop.ErrorFields = true
+	out := gop.Format(tz.Tokenize(data{1, errors.New("err"), map[string]interface{}{"a": 1, "b": 2}}), gop.ThemeNone)
+	gop.ErrorFields = false
+
+	g.Eq(out, "gop_test.data/* len=3 */{\n"+
+		"    ID: gop.Redacted().(int),\n"+
+		"    Err: gop.Redacted().(error),\n"+
+		"    Sub: gop.Obj/* len=2 */{\n"+
+		"        \"a\": gop.Redacted().(interface {}),\n"+
+		"        \"b\": 2,\n"+
+		"    },\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+	g.Nil(gop.Redacted())
+	g.Eq(gop.Plain(data{ID: 1}), "gop_test.data/* len=3 */{\n    ID: 1,\n    Err: nil,\n    Sub: gop.Obj{\n    },\n}")
+}
+
+func handler(string) int { return 0 }
+
+func TestPtrToFuncAndChan(t *testing.T) {
+	g := got.T(t)
+
+	var nf func()
+	var nc chan int
+	g.Eq(gop.Plain(&nf), "new(func())")
+	g.Eq(gop.Plain(&nc), "new(chan int)")
+	g.Nil(parser.ParseExpr(gop.Plain(&nf)))
+
+	f := func() {}
+	c := make(chan int, 1)
+	v := struct {
+		F *func()
+		C *chan int
+	}{&f, &c}
+
+	out, values := gop.FTemplate(v)
+	g.Eq(out, "struct { F *func(); C *chan int }/* len=2 */{\n"+
+		"    F: new(func())/* {{.ptr0}} */,\n"+
+		"    C: new(chan int)/* {{.ptr1}} */,\n"+
+		"}")
+	g.Eq(values["ptr1"], fmt.Sprintf("0x%x", reflect.ValueOf(c).Pointer()))
+	g.Nil(parser.ParseExpr(gop.Plain(v)))
+}
+
+type reflected struct {
+	A int `json:"a"`
+	b string
+	error
+}
+
+func (reflected) Method(int) string { return "" }
+
+func TestReflect(t *testing.T) {
+	g := got.T(t)
+
+	typ := reflect.TypeOf(reflected{})
+
+	out := gop.Plain(typ)
+	g.Eq(out, "reflect.TypeOf((*gop_test.reflected)(nil)).Elem()")
+	g.Nil(parser.ParseExpr(out))
+
+	out = gop.Plain([]reflect.StructField{typ.Field(0), typ.Field(1), typ.Field(2)})
+	g.Eq(out, "[]reflect.StructField/* len=3 cap=3 */{\n"+
+		"    reflect.StructField{\n"+
+		"        Name: \"A\",\n"+
+		"        Type: reflect.TypeOf((*int)(nil)).Elem(),\n"+
+		"        Tag: `json:\"a\"`,\n"+
+		"    },\n"+
+		"    reflect.StructField{\n"+
+		"        Name: \"b\",\n"+
+		"        PkgPath: `github.com/ysmood/got/lib/gop_test`/* len=34 */,\n"+
+		"        Type: reflect.TypeOf((*string)(nil)).Elem(),\n"+
+		"    },\n"+
+		"    reflect.StructField{\n"+
+		"        Name: \"error\",\n"+
+		"        PkgPath: `github.com/ysmood/got/lib/gop_test`/* len=34 */,\n"+
+		"        Type: reflect.TypeOf((*error)(nil)).Elem(),\n"+
+		"        Anonymous: true,\n"+
+		"    },\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+
+	m, _ := typ.MethodByName("Method")
+	out = gop.Plain(m)
+	g.Eq(out, "reflect.Method{\n"+
+		"    Name: \"Method\",\n"+
+		"    Type: reflect.TypeOf((*func(gop_test.reflected, int) string)(nil)).Elem(),\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+
+	g.Eq(gop.Plain([]reflect.Type{nil}), "[]reflect.Type/* len=1 cap=1 */{\n    nil,\n}")
+}
+
+func TestFuncNames(t *testing.T) {
+	g := got.T(t)
+
+	closure := func() {}
+	var nilFn func()
+
+	type handlers struct {
+		Named   func(string) int
+		Closure func()
+		Method  func() string
+		Nil     func()
+	}
+	v := handlers{handler, closure, time.Now().String, nilFn}
+
+	gop.FuncNames = true
+	out := gop.Plain(v)
+	gop.FuncNames = false
+
+	g.Eq(out, fmt.Sprintf("gop_test.handlers/* len=4 */{\n"+
+		"    Named: gop_test.handler/* func(string) int */,\n"+
+		"    Closure: (func())(nil)/* gop_test.TestFuncNames.func1 0x%x */,\n"+
+		"    Method: (func() string)(nil)/* time.Time.String */,\n"+
+		"    Nil: (func())(nil)/* 0x0 */,\n"+
+		"}", reflect.ValueOf(closure).Pointer()))
+	g.Nil(parser.ParseExpr(out))
+}
+
+type server struct{}
+
+func (s *server) handle() {}
+
+func (s server) name() string { return "" }
+
+func TestMethodValue(t *testing.T) {
+	g := got.T(t)
+
+	s := &server{}
+	out := gop.Plain([]interface{}{s.handle, s.name})
+
+	g.Eq(out, ""+
+		"gop.Arr/* len=2 cap=2 */{\n"+
+		"    (func())(nil)/* gop_test.(*server).handle */,\n"+
+		"    (func() string)(nil)/* gop_test.server.name */,\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+}
+
+func TestJSONView(t *testing.T) {
+	g := got.T(t)
+
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type user struct {
+		Base
+		Zeta   string            `json:"zeta"`
+		Alpha  []int             `json:"alpha,omitempty"`
+		Secret string            `json:"-"`
+		Tags   map[string]string `json:"tags"`
+		Name   string
+		hidden int
+	}
+
+	out := gop.JSONView(user{Base{1}, "z", nil, "s", map[string]string{"b": "2", "a": "1"}, "n", 1})
+	g.Eq(out, ""+
+		"gop.Obj/* len=4 */{\n"+
+		"    \"id\": 1,\n"+
+		"    \"zeta\": \"z\",\n"+
+		"    \"tags\": gop.Obj/* len=2 */{\n"+
+		"        \"a\": \"1\",\n"+
+		"        \"b\": \"2\",\n"+
+		"    },\n"+
+		"    \"Name\": \"n\",\n"+
+		"}")
+	g.Nil(parser.ParseExpr(out))
+
+	g.Eq(gop.JSONView([]interface{}{"a", nil, []int{1}, map[string]int{}}), ""+
+		"gop.Arr/* len=4 */{\n"+
+		"    \"a\",\n"+
+		"    nil,\n"+
+		"    gop.Arr{\n"+
+		"        1,\n"+
+		"    },\n"+
+		"    gop.Obj{\n"+
+		"    },\n"+
+		"}")
+
+	g.Eq(gop.JSONView([]interface{}{int64(1<<53 + 1), 1.5}), "gop.Arr/* len=2 */{\n    9007199254740993,\n    1.5,\n}")
+
+	_, err := json.Marshal(map[bool]int{true: 1})
+	g.Eq(gop.JSONView(map[bool]int{true: 1}), ""+
+		"/* "+err.Error()+" */map[bool]int{\n"+
+		"    true: 1,\n"+
+		"}")
+}
+
+func TestShort(t *testing.T) {
+	g := got.T(t)
+
+	type data struct {
+		I8  int8
+		P   *int
+		Nil *int
+		Fn  func()
+		Ch  chan int
+		Arr []interface{}
+		D   time.Duration
+	}
+
+	n := 1
+	ch := make(chan int, 1)
+	v := data{100, &n, nil, nil, ch, []interface{}{uint(1), "a", struct{ A int }{1}}, time.Second}
+
+	g.Eq(gop.Short(v), fmt.Sprintf("/* len=7 */{\n"+
+		"    I8: 100,\n"+
+		"    P: gop.Ptr(1),\n"+
+		"    Nil: nil,\n"+
+		"    Fn: nil/* 0x0 */,\n"+
+		"    Ch: make(chan int, 1)/* 0x%x */,\n"+
+		"    Arr: /* len=3 cap=3 */{\n"+
+		"        1,\n"+
+		"        \"a\",\n"+
+		"        {\n"+
+		"            A: 1,\n"+
+		"        },\n"+
+		"    },\n"+
+		"    D: \"1s\",\n"+
+		"}", reflect.ValueOf(ch).Pointer()))
+
+	g.Eq(gop.Short(unsafe.Pointer(&n)), fmt.Sprintf("%v", &n))
 }
 
 func TestPlain(t *testing.T) {
@@ -192,6 +1498,25 @@ func TestConvertors(t *testing.T) {
 	g.Eq(gop.JSONBytes(nil, "[1, 2]"), []byte("[1, 2]"))
 }
 
+func TestDurationRoundTrip(t *testing.T) {
+	g := got.T(t)
+
+	for _, c := range []struct {
+		d   time.Duration
+		out string
+	}{
+		{-90 * time.Minute, `gop.Duration("-1h30m0s")`},
+		{1500 * time.Microsecond, `gop.Duration("1.5ms")`},
+		{0, `gop.Duration("0s")`},
+		{time.Nanosecond, `gop.Duration("1ns")`},
+		{-time.Nanosecond, `gop.Duration("-1ns")`},
+		{math.MinInt64, "gop.Duration(`-2562047h47m16.854775808s`)"},
+	} {
+		g.Eq(gop.Plain(c.d), c.out)
+		g.Equal(gop.Duration(c.d.String()), c.d)
+	}
+}
+
 func TestGetPrivateFieldErr(t *testing.T) {
 	g := got.T(t)
 	g.Panic(func() {
@@ -223,3 +1548,44 @@ func TestTheme(t *testing.T) {
 	g := got.T(t)
 	g.Eq(gop.ThemeDefault(gop.Error), []gop.Style{gop.Underline, gop.Red})
 }
+
+func TestTable(t *testing.T) {
+	g := got.T(t)
+
+	type row struct {
+		Name string
+		Age  int
+		tag  string
+	}
+
+	g.Eq(gop.Table([]row{{"jack", 10, "天地"}, {"ann", 8, "a"}}), ""+
+		"Name   | Age | tag\n"+
+		"-------|-----|-----\n"+
+		"\"jack\" | 10  | \"天地\"\n"+
+		"\"ann\"  | 8   | \"a\"")
+
+	g.Eq(gop.Table([]interface{}{&row{Name: "a"}, row{Name: "b"}}), ""+
+		"Name | Age | tag\n"+
+		"-----|-----|----\n"+
+		"\"a\"  | 0   | \"\"\n"+
+		"\"b\"  | 0   | \"\"")
+
+	g.Eq(gop.Table([]map[string]int{{"b": 2, "a": 1}, {"a": 3, "b": 4}}), ""+
+		"a | b\n"+
+		"--|--\n"+
+		"1 | 2\n"+
+		"3 | 4")
+
+	for _, v := range []interface{}{
+		1,
+		[]int{},
+		[]int{1},
+		[]interface{}{row{}, nil},
+		[]interface{}{row{}, 1},
+		[]map[string]int{{"a": 1}, {"a": 1, "b": 2}},
+		[]map[string]int{{"a": 1}, {"b": 2}},
+		[]struct{ M map[int]int }{{map[int]int{1: 1}}},
+	} {
+		g.Eq(gop.Table(v), gop.F(v))
+	}
+}
@@ -0,0 +1,119 @@
+package gop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// Table renders a slice of structs, or a slice of maps with the same keys, as an aligned text table,
+// the columns are the fields or keys, the rows are the elements. It's for quickly scanning data, not for reconstruction.
+// If v isn't such uniform data, or any cell is multiline, it will fall back to F.
+func Table(v interface{}) string {
+	header, rows, ok := tableCells(reflect.ValueOf(v))
+	if !ok {
+		return F(v)
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	line := func(row []string) string {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+		return strings.TrimRight(strings.Join(cells, " | "), " ")
+	}
+
+	sep := make([]string, len(widths))
+	for i, w := range widths {
+		sep[i] = strings.Repeat("-", w)
+	}
+
+	lines := []string{line(header), strings.Join(sep, "-|-")}
+	for _, row := range rows {
+		lines = append(lines, line(row))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func tableCells(v reflect.Value) (header []string, rows [][]string, ok bool) {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() == 0 {
+		return nil, nil, false
+	}
+
+	first := tableElem(v.Index(0))
+	var keys []reflect.Value
+
+	switch first.Kind() {
+	case reflect.Struct:
+		for i := 0; i < first.NumField(); i++ {
+			header = append(header, first.Type().Field(i).Name)
+		}
+	case reflect.Map:
+		keys = sortMapKeys(seen{}, first)
+		for _, k := range keys {
+			header = append(header, fmt.Sprintf("%v", k.Interface()))
+		}
+	default:
+		return nil, nil, false
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		el := tableElem(v.Index(i))
+		if el.Kind() == reflect.Invalid || el.Type() != first.Type() {
+			return nil, nil, false
+		}
+
+		row := []string{}
+		if el.Kind() == reflect.Struct {
+			for j := 0; j < el.NumField(); j++ {
+				f := el.Field(j)
+				if !f.CanInterface() {
+					if !ReadPrivate {
+						row = append(row, "/* unexported */")
+						continue
+					}
+					f = GetPrivateField(el, j)
+				}
+				row = append(row, Plain(f.Interface()))
+			}
+		} else {
+			if el.Len() != len(keys) {
+				return nil, nil, false
+			}
+			for _, k := range keys {
+				val := el.MapIndex(k)
+				if !val.IsValid() {
+					return nil, nil, false
+				}
+				row = append(row, Plain(val.Interface()))
+			}
+		}
+
+		for _, cell := range row {
+			if strings.Contains(cell, "\n") {
+				return nil, nil, false
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows, true
+}
+
+// tableElem unwraps the interface and pointer of v
+func tableElem(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return v
+}
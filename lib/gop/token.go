@@ -1,12 +1,19 @@
 package gop
 
 import (
+	"encoding"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"reflect"
-	"sort"
+	"regexp"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 	"unicode"
 	"unicode/utf8"
@@ -18,6 +25,101 @@ var LongStringLen = 16
 // LongBytesLen is the length of that will be treated as long bytes
 var LongBytesLen = 16
 
+// StringPreview is the max number of chars to show for a string, 0 means no limit.
+// A longer string will be truncated to its head and tail, the output is marked as truncated with the number of chars,
+// because it can't be used to reconstruct the value.
+var StringPreview = 0
+
+// MapKeyPreview is similar with StringPreview, but it only applies to the string keys of maps,
+// so that the long keys won't make the layout hard to read. The keys are always sorted by their full values.
+var MapKeyPreview = 0
+
+// BytesAsHex renders all the byte values as hex, such as byte(0x61) for 'a', by default only the
+// non-printable ones are in hex. It's more uniform for the binary-heavy fixtures.
+var BytesAsHex = false
+
+// CapHints marks the slices that use less than half of their capacity as over-allocated in the len/cap comment,
+// such as /* len=1 cap=8 over-allocated */. The maps have no such hint because their capacity isn't exposed.
+var CapHints = false
+
+// EmbeddedInterfaceTypes annotates the embedded interface fields of structs with the interface type,
+// such as Reader: /* io.Reader */nil, it helps to debug the structs for dependency injection.
+var EmbeddedInterfaceTypes = false
+
+// CircularPath formats the path of gop.Circular as a comment, such as gop.Circular(/* $root.A.B[2] */),
+// it keeps the output short when the path has large keys, but the output can't be used to reconstruct the value.
+// The default nil renders each segment of the path as a value. JSONPath is a ready-to-use one.
+var CircularPath func(path []interface{}) string
+
+// RepeatRuns is the min length of a run of identical consecutive items in a slice or array to render the run
+// as one item via the Repeat helper, such as gop.Repeat(0, 8), 0 disables it. The len comment of the slice still
+// shows the full length.
+var RepeatRuns = 0
+
+// DrainChan renders the values buffered in the channels as a comment, such as /* buffered: [1, 2] */ .
+// It's dangerous, use it only for debugging: to read the values, each channel is drained without blocking and
+// the values are sent back, so it races with the other goroutines that use the channel, they may miss or
+// reorder the values, or fill the buffer before the values are sent back, then the values will be lost.
+var DrainChan = false
+
+// InlineStructWidth is the max width of a struct to render it on one line like gofmt does for the short composites,
+// such as gop_test.Point/* len=2 */{X: 1, Y: 2}, the width excludes the type name. The structs that contain
+// slices, maps, or structs are always multiline. 0 disables it.
+var InlineStructWidth = 0
+
+// MapKeyRange appends the range of the keys to the len comment of the maps that have an ordered key type
+// and at least MapKeyRange entries, such as map[int]string/* len=100 keys=1..100 */, it gives a sense
+// of a large map without reading all the entries. 0 disables it.
+var MapKeyRange = 0
+
+// ShowKind prepends the reflect.Kind of the root value as a comment, such as /* kind=slice */,
+// it's useful to tell a slice from an array or a map when debugging generic or reflection code.
+// The nested values aren't annotated to keep the output short.
+var ShowKind = false
+
+// ShowImplements prepends the interfaces in Implements that the type of the root value satisfies as a comment,
+// such as /* implements: error, fmt.Stringer */, the ones only satisfied by the pointer to the type are listed
+// separately, it's useful to debug why a type doesn't match an interface. The nested values aren't annotated.
+var ShowImplements = false
+
+// Implements is the list of the interfaces to check for ShowImplements, the own interfaces can be appended, such as:
+//     gop.Implements = append(gop.Implements, reflect.TypeOf((*Handler)(nil)).Elem())
+var Implements = []reflect.Type{
+	reflect.TypeOf((*error)(nil)).Elem(),
+	reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+	reflect.TypeOf((*io.Reader)(nil)).Elem(),
+	reflect.TypeOf((*io.Writer)(nil)).Elem(),
+	reflect.TypeOf((*io.Closer)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+	reflect.TypeOf((*json.Marshaler)(nil)).Elem(),
+}
+
+// UseTextMarshaler renders the values that implement encoding.TextMarshaler via gop.Text,
+// such as net.IP, it's more readable than the underlying data of them.
+var UseTextMarshaler = false
+
+// ShowTags appends the tag of each struct field as a comment after the field name,
+// it's useful when debugging the (un)marshaling of structs.
+var ShowTags = false
+
+// ShowInterfaceType wraps the value held by an interface with a conversion to the interface type,
+// such as interface {}(1), it's off by default because it's verbose for the values like gop.Arr .
+var ShowInterfaceType = false
+
+// FuncNames renders the named functions as their names with the signature as a comment, such as:
+//     gop_test.handler/* func(string) int */
+// The anonymous functions will still be rendered as nil with the resolved name and the address as a comment.
+var FuncNames = false
+
+// ReadPrivate reads the unexported struct fields via unsafe, such as the monotonic clock reading of time.Time .
+// Set it to false to render each unexported field as /* unexported */nil without touching its memory,
+// such as in the environments that forbid the reflection into private memory.
+var ReadPrivate = true
+
+// ErrorFields renders the struct fields of error interface type via gop.Err with the message of the error,
+// instead of the fields of the concrete error type, such as the error fields in the fixtures of API responses.
+var ErrorFields = false
+
 // Type of token
 type Type int
 
@@ -89,6 +191,13 @@ const (
 	StructField
 	// StructClose type
 	StructClose
+
+	// DiffInsert type, the inserted part of a diff, it's used by diff.Colorize
+	DiffInsert
+	// DiffDelete type, the deleted part of a diff
+	DiffDelete
+	// DiffChunk type, the header of a diff chunk
+	DiffChunk
 )
 
 // Token represents a symbol in value layout
@@ -99,7 +208,159 @@ type Token struct {
 
 // Tokenize a random Go value
 func Tokenize(v interface{}) []*Token {
-	return tokenize(seen{}, []interface{}{}, reflect.ValueOf(v))
+	return NewTokenizer().Tokenize(v)
+}
+
+// Tokenizer reuses its internal buffers between each Tokenize call, such as the map to detect circular references
+// and the memory of tokens. Use it when you have to dump values in a tight loop.
+// It's not safe for concurrent use.
+type Tokenizer struct {
+	// Redact masks the values it returns true for, such as the volatile IDs and timestamps
+	Redact Redactor
+
+	// NoTruncate ignores the options that truncate the output, such as StringPreview and MapKeyPreview,
+	// it's used when the output is for comparison rather than for display.
+	NoTruncate bool
+
+	seen   seen
+	addrs  map[*Token]bool
+	chunks [][]Token
+	chunk  int
+	offset int
+}
+
+// NewTokenizer instance
+func NewTokenizer() *Tokenizer {
+	return &Tokenizer{seen: seen{}}
+}
+
+// tokenizerChunkSize is the number of tokens that will be allocated at once
+const tokenizerChunkSize = 256
+
+// Reset the internal buffers, the tokens returned before will be overwritten by the next Tokenize call.
+func (tz *Tokenizer) Reset() {
+	for k := range tz.seen {
+		delete(tz.seen, k)
+	}
+	for t := range tz.addrs {
+		delete(tz.addrs, t)
+	}
+	tz.chunk = 0
+	tz.offset = 0
+}
+
+// Tokenize a random Go value. It will Reset the tokenizer before tokenizing.
+func (tz *Tokenizer) Tokenize(v interface{}) []*Token {
+	tz.Reset()
+
+	val := reflect.ValueOf(v)
+	ts := tz.tokenize(tz.seen, []interface{}{}, val)
+	if ShowImplements && val.IsValid() {
+		ts = append(tz.implements(val.Type()), ts...)
+	}
+	if ShowKind && val.IsValid() {
+		ts = append([]*Token{tz.comment("kind=" + val.Kind().String())}, ts...)
+	}
+	return ts
+}
+
+// implements returns the comments of the interfaces in Implements that t or the pointer to t satisfies
+func (tz *Tokenizer) implements(t reflect.Type) []*Token {
+	list, ptrList := []string{}, []string{}
+	for _, it := range Implements {
+		if t.Implements(it) {
+			list = append(list, it.String())
+		} else if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(it) {
+			ptrList = append(ptrList, it.String())
+		}
+	}
+
+	ts := []*Token{}
+	if len(list) > 0 {
+		ts = append(ts, tz.comment("implements: "+strings.Join(list, ", ")))
+	}
+	if len(ptrList) > 0 {
+		ts = append(ts, tz.comment("*"+t.String()+" implements: "+strings.Join(ptrList, ", ")))
+	}
+	return ts
+}
+
+func (tz *Tokenizer) token(t Type, literal string) *Token {
+	if tz.chunk == len(tz.chunks) {
+		tz.chunks = append(tz.chunks, make([]Token, tokenizerChunkSize))
+	}
+
+	token := &tz.chunks[tz.chunk][tz.offset]
+	token.Type = t
+	token.Literal = literal
+
+	tz.offset++
+	if tz.offset == tokenizerChunkSize {
+		tz.chunk++
+		tz.offset = 0
+	}
+
+	return token
+}
+
+// comment returns a Comment token of s, s will be escaped via escapeComment
+func (tz *Tokenizer) comment(s string) *Token {
+	return tz.token(Comment, "/* "+escapeComment(s)+" */")
+}
+
+// addr marks t as the token that holds a volatile address, such as the one of a chan, so that FTemplate can replace it
+func (tz *Tokenizer) addr(t *Token) *Token {
+	if tz.addrs == nil {
+		tz.addrs = map[*Token]bool{}
+	}
+	tz.addrs[t] = true
+	return t
+}
+
+// escapeComment neutralizes the end of comment and the control chars in s,
+// so that the data in s, such as a struct tag, can't break the output as valid golang syntax.
+func escapeComment(s string) string {
+	out := ""
+	for _, r := range strings.ReplaceAll(s, "*/", "*\\/") {
+		if unicode.IsControl(r) {
+			out += fmt.Sprintf("\\x%02x", r)
+		} else {
+			out += string(r)
+		}
+	}
+	return out
+}
+
+var tokenizerPool = sync.Pool{
+	New: func() interface{} { return NewTokenizer() },
+}
+
+// format v with a pooled Tokenizer
+func format(v interface{}, theme Theme) string {
+	tz := tokenizerPool.Get().(*Tokenizer)
+	defer tokenizerPool.Put(tz)
+	return Format(tz.Tokenize(v), theme)
+}
+
+// Redactor decides if the value at the path should be masked, the path is from the root value,
+// each segment is a struct field name, map key, or slice index.
+type Redactor func(path []interface{}) bool
+
+// RedactPaths returns a Redactor that masks the values whose path matches any item of the list,
+// an item matches if it equals the last segment of the path or the segments joined by ".", such as "ID" or "User.ID".
+func RedactPaths(list ...string) Redactor {
+	return func(p []interface{}) bool {
+		full := make([]string, len(p))
+		for i, seg := range p {
+			full[i] = fmt.Sprintf("%v", seg)
+		}
+		for _, item := range list {
+			if item == full[len(full)-1] || item == strings.Join(full, ".") {
+				return true
+			}
+		}
+		return false
+	}
 }
 
 // Any type
@@ -124,6 +385,20 @@ func Circular(path ...interface{}) interface{} {
 	return nil
 }
 
+// Redacted value of the path masked by a Redactor
+func Redacted() interface{} {
+	return nil
+}
+
+// Repeat returns n copies of v, it stands for a run of identical consecutive items in the output of RepeatRuns
+func Repeat(v interface{}, n int) []interface{} {
+	list := make([]interface{}, n)
+	for i := range list {
+		list[i] = v
+	}
+	return list
+}
+
 // Base64 returns the []byte that s represents
 func Base64(s string) []byte {
 	b, _ := base64.StdEncoding.DecodeString(s)
@@ -152,52 +427,147 @@ func JSONBytes(v interface{}, raw string) []byte {
 	return []byte(raw)
 }
 
+// Text returns the value unmarshaled from s via encoding.TextUnmarshaler,
+// typ should be a nil pointer to the type of the value, such as (*net.IP)(nil) .
+func Text(s string, typ interface{}) interface{} {
+	v := reflect.New(reflect.TypeOf(typ).Elem())
+	_ = v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	return v.Elem().Interface()
+}
+
+// Err returns an error with the message s,
+// typ should be a nil pointer of the concrete type of the error, it's only used to make the output readable.
+func Err(s string, typ interface{}) error {
+	return errors.New(s)
+}
+
 type path []interface{}
 
-func (p path) tokens() []*Token {
+func (tz *Tokenizer) pathTokens(p path) []*Token {
 	sn := map[uintptr]path{}
 	ts := []*Token{}
 	for i, seg := range p {
-		ts = append(ts, tokenize(sn, []interface{}{}, reflect.ValueOf(seg))...)
+		ts = append(ts, tz.tokenize(sn, []interface{}{}, reflect.ValueOf(seg))...)
 		if i < len(p)-1 {
-			ts = append(ts, &Token{InlineComma, ","})
+			ts = append(ts, tz.token(InlineComma, ","))
 		}
 	}
 	return ts
 }
 
+var regIdentifier = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+// JSONPath formats the path like $root.A.B[2], the keys that aren't identifiers are quoted, such as $root["a b"]
+func JSONPath(path []interface{}) string {
+	var b strings.Builder
+	b.WriteString("$root")
+	for _, seg := range path {
+		if s, ok := seg.(string); ok {
+			if regIdentifier.MatchString(s) {
+				b.WriteString("." + s)
+			} else {
+				b.WriteString("[" + strconv.Quote(s) + "]")
+			}
+			continue
+		}
+		b.WriteString(fmt.Sprintf("[%v]", seg))
+	}
+	return b.String()
+}
+
 type seen map[uintptr]path
 
-func (sn seen) circular(p path, v reflect.Value) []*Token {
+// circular returns the tokens of a reference to the path where v is first seen.
+// For a slice, it's only circular when the prior one is an ancestor of p,
+// otherwise the slice shares the backing array with the prior one and alias will be true.
+func (tz *Tokenizer) circular(sn seen, p path, v reflect.Value) (ts []*Token, alias bool) {
 	switch v.Kind() {
 	case reflect.Ptr, reflect.Map, reflect.Slice:
+		// all the nil values share the same address
+		if v.IsNil() {
+			break
+		}
+		// empty slices may share the same address without sharing any element
+		if v.Kind() == reflect.Slice && v.Cap() == 0 {
+			break
+		}
 		ptr := v.Pointer()
-		if p, has := sn[ptr]; has {
-			ts := []*Token{{Func, "gop.Circular"}, {ParenOpen, "("}}
-			ts = append(ts, p.tokens()...)
-			return append(ts, &Token{ParenClose, ")"}, &Token{Dot, "."},
-				&Token{ParenOpen, "("}, typeName(v.Type().String()), &Token{ParenClose, ")"})
+		if prior, has := sn[ptr]; has {
+			if v.Kind() == reflect.Slice && !prior.ancestorOf(p) {
+				sn[ptr] = append(path{}, p...)
+				return nil, true
+			}
+			ts := []*Token{tz.token(Func, "gop.Circular"), tz.token(ParenOpen, "(")}
+			if CircularPath == nil {
+				ts = append(ts, tz.pathTokens(prior)...)
+			} else {
+				ts = append(ts, tz.comment(CircularPath(prior)))
+			}
+			return append(ts, tz.token(ParenClose, ")"), tz.token(Dot, "."),
+				tz.token(ParenOpen, "("), tz.typeName(v.Type().String()), tz.token(ParenClose, ")")), false
 		}
-		sn[ptr] = p
+		sn[ptr] = append(path{}, p...)
 	}
 
-	return nil
+	return nil, false
+}
+
+// runLen returns the number of the items from index i of the list v that are identical to the item at i
+func runLen(v reflect.Value, i int) int {
+	n := 1
+	for ; i+n < v.Len() && reflect.DeepEqual(v.Index(i).Interface(), v.Index(i+n).Interface()); n++ {
+	}
+	return n
+}
+
+func (p path) ancestorOf(c path) bool {
+	if len(p) > len(c) {
+		return false
+	}
+	for i, seg := range p {
+		if seg != c[i] {
+			return false
+		}
+	}
+	return true
 }
 
-func tokenize(sn seen, p path, v reflect.Value) []*Token {
-	if ts, has := tokenizeSpecial(v); has {
+func (tz *Tokenizer) tokenize(sn seen, p path, v reflect.Value) (ts []*Token) {
+	// the methods called on the value, such as Error or MarshalText, may panic
+	defer func() {
+		if r := recover(); r != nil {
+			ts = tz.unreadable()
+		}
+	}()
+
+	if tz.redacted(p) {
+		return []*Token{tz.token(Func, "gop.Redacted"), tz.token(ParenOpen, "("), tz.token(ParenClose, ")"),
+			tz.token(Dot, "."), tz.token(ParenOpen, "("), tz.typeName(v.Type().String()), tz.token(ParenClose, ")")}
+	}
+
+	if ts, has := tz.tokenizeSpecial(v); has {
+		return ts
+	} else if ts, has := tz.tokenizeReflect(sn, p, v); has {
+		return ts
+	} else if ts, has := tz.tokenizeAtomic(sn, p, v); has {
 		return ts
 	}
 
-	if ts := sn.circular(p, v); ts != nil {
+	ts, alias := tz.circular(sn, p, v)
+	if ts != nil {
 		return ts
 	}
 
-	t := &Token{Nil, ""}
+	t := tz.token(Nil, "")
 
 	switch v.Kind() {
 	case reflect.Interface:
-		return tokenize(sn, p, v.Elem())
+		if ShowInterfaceType && !v.IsNil() {
+			ts := []*Token{tz.typeName(v.Type().String()), tz.token(ParenOpen, "(")}
+			ts = append(ts, tz.tokenize(sn, p, v.Elem())...)
+			return append(ts, tz.token(ParenClose, ")"))
+		}
+		return tz.tokenize(sn, p, v.Elem())
 
 	case reflect.Bool:
 		t.Type = Bool
@@ -208,133 +578,518 @@ func tokenize(sn seen, p path, v reflect.Value) []*Token {
 		}
 
 	case reflect.String:
-		return tokenizeString(v)
+		return tz.tokenizeString(v)
 
 	case reflect.Chan:
 		if v.Cap() == 0 {
-			return []*Token{{Func, "make"}, {ParenOpen, "("},
-				{Chan, "chan"}, typeName(v.Type().Elem().String()), {ParenClose, ")"},
-				{Comment, fmt.Sprintf("/* 0x%x */", v.Pointer())}}
+			return []*Token{tz.token(Func, "make"), tz.token(ParenOpen, "("),
+				tz.token(Chan, "chan"), tz.typeName(v.Type().Elem().String()), tz.token(ParenClose, ")"),
+				tz.addr(tz.comment(fmt.Sprintf("0x%x", v.Pointer())))}
 		}
-		return []*Token{{Func, "make"}, {ParenOpen, "("}, {Chan, "chan"},
-			typeName(v.Type().Elem().Name()), {InlineComma, ","},
-			{Number, fmt.Sprintf("%d", v.Cap())}, {ParenClose, ")"},
-			{Comment, fmt.Sprintf("/* 0x%x */", v.Pointer())}}
+		ts := []*Token{tz.token(Func, "make"), tz.token(ParenOpen, "("), tz.token(Chan, "chan"),
+			tz.typeName(v.Type().Elem().Name()), tz.token(InlineComma, ","),
+			tz.token(Number, fmt.Sprintf("%d", v.Cap())), tz.token(ParenClose, ")"),
+			tz.addr(tz.comment(fmt.Sprintf("0x%x", v.Pointer())))}
+		if DrainChan {
+			ts = append(ts, tz.tokenizeBuffered(v)...)
+		}
+		return ts
 
 	case reflect.Func:
-		return []*Token{{ParenOpen, "("}, {TypeName, v.Type().String()},
-			{ParenClose, ")"}, {ParenOpen, "("}, {Nil, "nil"}, {ParenClose, ")"},
-			{Comment, fmt.Sprintf("/* 0x%x */", v.Pointer())}}
+		return tz.tokenizeFunc(v)
 
 	case reflect.Ptr:
-		return tokenizePtr(sn, p, v)
+		return tz.tokenizePtr(sn, p, v)
 
 	case reflect.UnsafePointer:
-		return []*Token{typeName("unsafe.Pointer"), {ParenOpen, "("}, typeName("uintptr"),
-			{ParenOpen, "("}, typeName(fmt.Sprintf("%v", v.Interface())), {ParenClose, ")"}, {ParenClose, ")"}}
+		return []*Token{tz.typeName("unsafe.Pointer"), tz.token(ParenOpen, "("), tz.typeName("uintptr"),
+			tz.token(ParenOpen, "("), tz.addr(tz.typeName(fmt.Sprintf("0x%x", v.Pointer()))), tz.token(ParenClose, ")"), tz.token(ParenClose, ")")}
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.Float32, reflect.Float64,
 		reflect.Uintptr, reflect.Complex64, reflect.Complex128:
-		return tokenizeNumber(v)
+		return tz.tokenizeNumber(v)
 
 	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
-		return tokenizeCollection(sn, p, v)
+		return tz.tokenizeCollection(sn, p, v, alias)
 	}
 
 	return []*Token{t}
 }
 
-func tokenizeSpecial(v reflect.Value) ([]*Token, bool) {
+var regAnonymousFunc = regexp.MustCompile(`\.func\d+(\.\d+)*$`)
+var regFuncName = regexp.MustCompile(`^[\w.]+$`)
+
+// tokenizeBuffered drains the buffered values of the channel v and sends them back in the same order
+func (tz *Tokenizer) tokenizeBuffered(v reflect.Value) []*Token {
+	if v.Type().ChanDir() != reflect.BothDir || v.Len() == 0 {
+		return nil
+	}
+
+	// only take the values buffered so far, the other goroutines may keep sending
+	n := v.Len()
+	list := []reflect.Value{}
+	for x, ok := v.TryRecv(); ok; x, ok = v.TryRecv() {
+		list = append(list, x)
+		if len(list) == n {
+			break
+		}
+	}
+
+	items := []string{}
+	for _, x := range list {
+		v.TrySend(x)
+		items = append(items, Short(x.Interface()))
+	}
+	return []*Token{tz.comment("buffered: [" + strings.Join(items, ", ") + "]")}
+}
+
+func (tz *Tokenizer) tokenizeFunc(v reflect.Value) []*Token {
+	comment := fmt.Sprintf("0x%x", v.Pointer())
+
+	if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+		// remove the import path of the package
+		name := fn.Name()
+		name = name[strings.LastIndex(name, "/")+1:]
+
+		switch {
+		case strings.HasSuffix(name, "-fm"):
+			// a bound method value, such as obj.Method, the name carries the receiver type,
+			// the address is omitted because it's shared by all the values of the method
+			comment = strings.TrimSuffix(name, "-fm")
+
+		case !FuncNames:
+
+		case !regAnonymousFunc.MatchString(name) && regFuncName.MatchString(name):
+			return []*Token{tz.token(Func, name), tz.comment(v.Type().String())}
+
+		default:
+			comment = name + " " + comment
+		}
+	}
+
+	return []*Token{tz.token(ParenOpen, "("), tz.token(TypeName, v.Type().String()),
+		tz.token(ParenClose, ")"), tz.token(ParenOpen, "("), tz.token(Nil, "nil"), tz.token(ParenClose, ")"),
+		tz.addr(tz.comment(comment))}
+}
+
+// unreadable is for the value that panics when it's being tokenized
+func (tz *Tokenizer) unreadable() []*Token {
+	return []*Token{tz.token(Nil, "nil"), tz.comment("unreadable")}
+}
+
+func (tz *Tokenizer) redacted(p path) bool {
+	return tz.Redact != nil && len(p) > 0 && tz.Redact(p)
+}
+
+func (tz *Tokenizer) tokenizeSpecial(v reflect.Value) ([]*Token, bool) {
 	if v.Kind() == reflect.Invalid {
-		return []*Token{{Nil, "nil"}}, true
+		return []*Token{tz.token(Nil, "nil")}, true
 	} else if r, ok := v.Interface().(rune); ok && unicode.IsGraphic(r) {
-		return []*Token{tokenizeRune(&Token{Nil, ""}, r)}, true
+		return []*Token{tz.tokenizeRune(tz.token(Nil, ""), r)}, true
 	} else if b, ok := v.Interface().(byte); ok {
-		return tokenizeByte(&Token{Nil, ""}, b), true
+		return tz.tokenizeByte(tz.token(Nil, ""), b), true
 	} else if t, ok := v.Interface().(time.Time); ok {
-		return tokenizeTime(t), true
+		return tz.tokenizeTime(t), true
 	} else if d, ok := v.Interface().(time.Duration); ok {
-		return tokenizeDuration(d), true
+		return tz.tokenizeDuration(d), true
+	} else if ts, ok := tz.tokenizeSync(v); ok {
+		return ts, true
+	} else if ts, ok := tz.tokenizeEnum(v); ok {
+		return ts, true
+	} else if ts, ok := tz.tokenizeStringer(v); ok {
+		return ts, true
+	} else if ts, ok := tz.tokenizeText(v); ok {
+		return ts, true
 	}
 
-	return tokenizeJSON(v)
+	return tz.tokenizeJSON(v)
 }
 
-func tokenizeCollection(sn seen, p path, v reflect.Value) []*Token {
+var mutexType = reflect.TypeOf((*sync.Mutex)(nil)).Elem()
+var rwMutexType = reflect.TypeOf((*sync.RWMutex)(nil)).Elem()
+var onceType = reflect.TypeOf((*sync.Once)(nil)).Elem()
+var waitGroupType = reflect.TypeOf((*sync.WaitGroup)(nil)).Elem()
+
+// tokenizeSync renders the synchronization primitives as their zero values with the state as a comment,
+// their internal fields are noise for debugging. The lock state is probed on a copy, so v won't be affected,
+// but the state is only a snapshot of the moment v is copied, it may be stale when it's rendered.
+func (tz *Tokenizer) tokenizeSync(v reflect.Value) ([]*Token, bool) {
+	switch v.Type() {
+	case mutexType, rwMutexType, onceType, waitGroupType:
+	default:
+		return nil, false
+	}
+
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+
+	state := ""
+	switch l := c.Interface().(type) {
+	case *sync.Mutex:
+		state = "unlocked"
+		if !l.TryLock() {
+			state = "locked"
+		}
+	case *sync.RWMutex:
+		state = "unlocked"
+		if !l.TryRLock() {
+			state = "locked"
+		} else {
+			l.RUnlock()
+			if !l.TryLock() {
+				state = "read-locked"
+			}
+		}
+	case *sync.Once:
+		// calling Do on the copy would block forever if the copy is taken while the Do of v is running
+		state = "not done"
+		if onceDone(c.Elem()) {
+			state = "done"
+		}
+	}
+
+	ts := []*Token{tz.typeName(v.Type().String() + "{}")}
+	if state != "" {
+		ts = append(ts, tz.comment(state))
+	}
+	return ts, true
+}
+
+// onceDone reads the done field of the sync.Once v, it's an uint32 or an atomic type that wraps an uint32
+func onceDone(v reflect.Value) bool {
+	done := GetPrivateFieldByName(v, "done")
+	for done.Kind() == reflect.Struct {
+		done = GetPrivateFieldByName(done, "v")
+	}
+	return done.Uint() != 0
+}
+
+// tokenizeAtomic renders the types of sync/atomic, such as atomic.Value and atomic.Int64, as the value returned
+// by their Load method with the type as a comment, their internal fields are noise for debugging.
+// The Load is called on a copy of v, so the value is only a snapshot that may be stale when it's rendered.
+func (tz *Tokenizer) tokenizeAtomic(sn seen, p path, v reflect.Value) ([]*Token, bool) {
+	load, ok := reflect.Method{}, false
+	if v.Type().PkgPath() == "sync/atomic" {
+		load, ok = reflect.PtrTo(v.Type()).MethodByName("Load")
+	}
+	if !ok {
+		return nil, false
+	}
+
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+
+	val := load.Func.Call([]reflect.Value{c})[0]
+	if val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
+
+	return append([]*Token{tz.comment(v.Type().String())}, tz.tokenize(sn, p, val)...), true
+}
+
+// rtypeType is the type of the values that implement reflect.Type
+var rtypeType = reflect.TypeOf(reflect.TypeOf(0))
+var structFieldType = reflect.TypeOf(reflect.StructField{})
+var methodType = reflect.TypeOf(reflect.Method{})
+
+// tokenizeReflect renders the values of reflection concisely instead of the runtime internals of them.
+// A reflect.Type is rendered as reflect.TypeOf((*T)(nil)).Elem(), a reflect.StructField only keeps the name,
+// type, tag, and whether it's embedded, a reflect.Method only keeps the name and signature.
+func (tz *Tokenizer) tokenizeReflect(sn seen, p path, v reflect.Value) ([]*Token, bool) {
+	var fields []string
+
+	switch v.Type() {
+	case structFieldType:
+		fields = []string{"Name", "PkgPath", "Type", "Tag", "Anonymous"}
+	case methodType:
+		fields = []string{"Name", "PkgPath", "Type"}
+	case rtypeType:
+		t := v.Interface().(reflect.Type)
+		return []*Token{tz.token(Func, "reflect.TypeOf"), tz.token(ParenOpen, "("), tz.token(ParenOpen, "("),
+			tz.typeName("*" + t.String()), tz.token(ParenClose, ")"), tz.token(ParenOpen, "("),
+			tz.token(Nil, "nil"), tz.token(ParenClose, ")"), tz.token(ParenClose, ")"), tz.token(Dot, "."),
+			tz.token(Func, "Elem"), tz.token(ParenOpen, "("), tz.token(ParenClose, ")")}, true
+	default:
+		return nil, false
+	}
+
+	ts := []*Token{tz.typeName(v.Type().String()), tz.token(StructOpen, "{")}
+	for _, name := range fields {
+		f := v.FieldByName(name)
+		if name != "Name" && name != "Type" && f.IsZero() {
+			continue
+		}
+		ts = append(ts, tz.token(StructKey, ""), tz.token(StructField, name), tz.token(Colon, ":"))
+		ts = append(ts, tz.tokenize(sn, append(p, name), f)...)
+		ts = append(ts, tz.token(Comma, ","))
+	}
+	return append(ts, tz.token(StructClose, "}")), true
+}
+
+func (tz *Tokenizer) tokenizeEnum(v reflect.Value) ([]*Token, bool) {
+	name, ok := enumName(v)
+	if !ok {
+		return nil, false
+	}
+
+	n := ""
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = strconv.FormatUint(v.Uint(), 10)
+	default:
+		n = strconv.FormatInt(v.Int(), 10)
+	}
+
+	return []*Token{tz.token(Number, name), tz.comment(n)}, true
+}
+
+// tokenizeStringer renders the named integers that implement fmt.Stringer, such as the constants defined via iota,
+// as the number with the name as a comment, such as time.Month(3)/* March */.
+func (tz *Tokenizer) tokenizeStringer(v reflect.Value) ([]*Token, bool) {
+	n := ""
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = strconv.FormatUint(v.Uint(), 10)
+	default:
+		return nil, false
+	}
+
+	s, ok := v.Interface().(fmt.Stringer)
+	if !ok || v.Type().Name() == "" {
+		return nil, false
+	}
+
+	name, ok := stringerName(s)
+	if !ok || name == v.Type().Name()+"("+n+")" {
+		return nil, false
+	}
+	if _, err := strconv.ParseFloat(name, 64); err == nil {
+		return nil, false
+	}
+
+	return []*Token{tz.typeName(v.Type().String()), tz.token(ParenOpen, "("), tz.token(Number, n),
+		tz.token(ParenClose, ")"), tz.comment(name)}, true
+}
+
+// stringerName calls the String method of s, ok is false if it panics or returns an empty string
+func stringerName(s fmt.Stringer) (name string, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+
+	name = s.String()
+	return name, name != ""
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func (tz *Tokenizer) tokenizeText(v reflect.Value) ([]*Token, bool) {
+	if !UseTextMarshaler || v.Kind() == reflect.Ptr || !reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return nil, false
+	}
+
+	m, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok {
+		return nil, false
+	}
+
+	b, err := m.MarshalText()
+	if err != nil {
+		return nil, false
+	}
+
+	t := v.Type().String()
+	return []*Token{tz.token(Func, "gop.Text"), tz.token(ParenOpen, "("), tz.token(String, string(b)),
+		tz.token(InlineComma, ","), tz.token(ParenOpen, "("), tz.typeName("*" + t), tz.token(ParenClose, ")"),
+		tz.token(ParenOpen, "("), tz.token(Nil, "nil"), tz.token(ParenClose, ")"), tz.token(ParenClose, ")"),
+		tz.token(Dot, "."), tz.token(ParenOpen, "("), tz.typeName(t), tz.token(ParenClose, ")")}, true
+}
+
+func (tz *Tokenizer) tokenizeCollection(sn seen, p path, v reflect.Value, alias bool) []*Token {
 	ts := []*Token{}
 
 	switch v.Kind() {
 	case reflect.Slice, reflect.Array:
 		if data, ok := v.Interface().([]byte); ok {
-			ts = append(ts, tokenizeBytes(data)...)
+			ts = append(ts, tz.tokenizeBytes(data)...)
+			if alias {
+				ts = append(ts, tz.comment("aliases prior slice"))
+			}
+			break
+		} else if data, ok := v.Interface().([]rune); ok && graphicRunes(data) {
+			ts = append(ts, tz.tokenizeRunes(data)...)
+			if alias {
+				ts = append(ts, tz.comment("aliases prior slice"))
+			}
 			break
 		} else {
-			ts = append(ts, typeName(v.Type().String()))
+			ts = append(ts, tz.typeName(v.Type().String()))
 		}
 		if v.Kind() == reflect.Slice {
-			ts = append(ts, &Token{Comment, fmt.Sprintf("/* len=%d cap=%d */", v.Len(), v.Cap())})
+			c := fmt.Sprintf("len=%d cap=%d", v.Len(), v.Cap())
+			if CapHints && v.Len() < v.Cap()/2 {
+				c += " over-allocated"
+			}
+			ts = append(ts, tz.comment(c))
 		}
-		ts = append(ts, &Token{SliceOpen, "{"})
+		if alias {
+			ts = append(ts, tz.comment("aliases prior slice"))
+		}
+		ts = append(ts, tz.token(SliceOpen, "{"))
 		for i := 0; i < v.Len(); i++ {
 			p := append(p, i)
 			el := v.Index(i)
-			ts = append(ts, &Token{SliceItem, ""})
-			ts = append(ts, tokenize(sn, p, el)...)
-			ts = append(ts, &Token{Comma, ","})
+			ts = append(ts, tz.token(SliceItem, ""))
+			n := 1
+			if RepeatRuns > 0 {
+				n = runLen(v, i)
+			}
+			if RepeatRuns > 0 && n >= RepeatRuns {
+				ts = append(ts, tz.token(Func, "gop.Repeat"), tz.token(ParenOpen, "("))
+				ts = append(ts, tz.tokenize(sn, p, el)...)
+				ts = append(ts, tz.token(InlineComma, ","), tz.token(Number, strconv.Itoa(n)),
+					tz.token(ParenClose, ")"))
+				i += n - 1
+			} else {
+				ts = append(ts, tz.tokenize(sn, p, el)...)
+			}
+			ts = append(ts, tz.token(Comma, ","))
 		}
-		ts = append(ts, &Token{SliceClose, "}"})
+		ts = append(ts, tz.token(SliceClose, "}"))
 
 	case reflect.Map:
-		ts = append(ts, typeName(v.Type().String()))
-		keys := v.MapKeys()
-		sort.Slice(keys, func(i, j int) bool {
-			return compare(keys[i].Interface(), keys[j].Interface()) < 0
-		})
+		ts = append(ts, tz.typeName(v.Type().String()))
+		keys := mapKeys(sn, v)
 		if len(keys) > 1 {
-			ts = append(ts, &Token{Comment, fmt.Sprintf("/* len=%d */", len(keys))})
+			c := fmt.Sprintf("len=%d", len(keys))
+			if r, ok := keyRange(keys); ok && MapKeyRange > 0 && len(keys) >= MapKeyRange {
+				c += " keys=" + r
+			}
+			ts = append(ts, tz.comment(c))
 		}
-		ts = append(ts, &Token{MapOpen, "{"})
+		ts = append(ts, tz.token(MapOpen, "{"))
 		for _, k := range keys {
 			p := append(p, k.Interface())
-			ts = append(ts, &Token{MapKey, ""})
-			ts = append(ts, tokenize(sn, p, k)...)
-			ts = append(ts, &Token{Colon, ":"})
-			ts = append(ts, tokenize(sn, p, v.MapIndex(k))...)
-			ts = append(ts, &Token{Comma, ","})
+			ts = append(ts, tz.token(MapKey, ""))
+			// the key itself shouldn't be redacted, so use the path of the map
+			ts = append(ts, tz.tokenizeMapKey(sn, p[:len(p)-1], k)...)
+			ts = append(ts, tz.token(Colon, ":"))
+			ts = append(ts, tz.tokenize(sn, p, v.MapIndex(k))...)
+			ts = append(ts, tz.token(Comma, ","))
 		}
-		ts = append(ts, &Token{MapClose, "}"})
+		ts = append(ts, tz.token(MapClose, "}"))
 
 	case reflect.Struct:
 		t := v.Type()
 
-		ts = append(ts, typeName(t.String()))
+		ts = append(ts, tz.typeName(t.String()))
 		if v.NumField() > 1 {
-			ts = append(ts, &Token{Comment, fmt.Sprintf("/* len=%d */", v.NumField())})
+			ts = append(ts, tz.comment(fmt.Sprintf("len=%d", v.NumField())))
 		}
-		ts = append(ts, &Token{StructOpen, "{"})
+		ts = append(ts, tz.token(StructOpen, "{"))
 		for i := 0; i < v.NumField(); i++ {
 			name := t.Field(i).Name
-			ts = append(ts, &Token{StructKey, ""})
-			ts = append(ts, &Token{StructField, name})
+			ts = append(ts, tz.token(StructKey, ""))
+			ts = append(ts, tz.token(StructField, name))
+			if tag := t.Field(i).Tag; ShowTags && tag != "" {
+				ts = append(ts, tz.comment(string(tag)))
+			}
 
 			f := v.Field(i)
-			if !f.CanInterface() {
+			private := !f.CanInterface()
+			if private && ReadPrivate {
 				f = GetPrivateField(v, i)
 			}
-			ts = append(ts, &Token{Colon, ":"})
-			ts = append(ts, tokenize(sn, append(p, name), f)...)
-			ts = append(ts, &Token{Comma, ","})
+			ts = append(ts, tz.token(Colon, ":"))
+			if ft := t.Field(i); EmbeddedInterfaceTypes && ft.Anonymous && ft.Type.Kind() == reflect.Interface {
+				ts = append(ts, tz.comment(ft.Type.String()))
+			}
+			if private && !ReadPrivate {
+				ts = append(ts, tz.comment("unexported"), tz.token(Nil, "nil"))
+			} else if ets, ok := tz.tokenizeErrField(f); ok && !tz.redacted(append(p, name)) {
+				ts = append(ts, ets...)
+			} else {
+				ts = append(ts, tz.tokenize(sn, append(p, name), f)...)
+			}
+			ts = append(ts, tz.token(Comma, ","))
 		}
-		ts = append(ts, &Token{StructClose, "}"})
+		ts = append(ts, tz.token(StructClose, "}"))
 	}
 
 	return ts
 }
 
-func tokenizeNumber(v reflect.Value) []*Token {
-	t := &Token{Nil, ""}
+// keyRange returns the min and max of the keys as "min..max", ok is false if the keys are not ordered,
+// keys should have at least one item
+func keyRange(keys []reflect.Value) (r string, ok bool) {
+	min, max := keys[0], keys[0]
+	var less func(x, y reflect.Value) bool
+
+	switch keys[0].Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(x, y reflect.Value) bool { return x.Int() < y.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less = func(x, y reflect.Value) bool { return x.Uint() < y.Uint() }
+	case reflect.Float32, reflect.Float64:
+		less = func(x, y reflect.Value) bool { return x.Float() < y.Float() }
+	case reflect.String:
+		less = func(x, y reflect.Value) bool { return x.String() < y.String() }
+	default:
+		return "", false
+	}
+
+	for _, k := range keys[1:] {
+		if less(k, min) {
+			min = k
+		}
+		if less(max, k) {
+			max = k
+		}
+	}
+
+	format := func(k reflect.Value) string {
+		if k.Kind() == reflect.String {
+			return strconv.Quote(k.String())
+		}
+		return formatNumber(k)
+	}
+
+	return format(min) + ".." + format(max), true
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func (tz *Tokenizer) tokenizeErrField(f reflect.Value) (ts []*Token, ok bool) {
+	// such as the Error of a nil pointer
+	defer func() {
+		if r := recover(); r != nil {
+			ts, ok = tz.unreadable(), true
+		}
+	}()
+
+	if !ErrorFields || f.Type() != errorType || f.IsNil() {
+		return nil, false
+	}
+
+	t := f.Elem().Type().String()
+	if f.Elem().Kind() != reflect.Ptr {
+		t = "*" + t
+	}
+
+	return []*Token{tz.token(Func, "gop.Err"), tz.token(ParenOpen, "("),
+		tz.token(Error, strconv.Quote(f.Interface().(error).Error())), tz.token(InlineComma, ","),
+		tz.token(ParenOpen, "("), tz.typeName(t), tz.token(ParenClose, ")"),
+		tz.token(ParenOpen, "("), tz.token(Nil, "nil"), tz.token(ParenClose, ")"), tz.token(ParenClose, ")")}, true
+}
+
+func (tz *Tokenizer) tokenizeNumber(v reflect.Value) []*Token {
+	t := tz.token(Nil, "")
 	ts := []*Token{}
 
 	switch v.Kind() {
@@ -345,126 +1100,262 @@ func tokenizeNumber(v reflect.Value) []*Token {
 
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Float32, reflect.Float64,
-		reflect.Uintptr:
+		reflect.Float32, reflect.Float64:
 
-		ts = append(ts, typeName(v.Type().Name()), &Token{ParenOpen, "("})
+		ts = append(ts, tz.typeName(v.Type().Name()), tz.token(ParenOpen, "("))
 		t.Type = Number
-		t.Literal = fmt.Sprintf("%v", v.Interface())
-		ts = append(ts, t, &Token{ParenClose, ")"})
+		t.Literal = formatNumber(v)
+		ts = append(ts, t, tz.token(ParenClose, ")"))
 
-	case reflect.Complex64:
-		ts = append(ts, typeName(v.Type().Name()), &Token{ParenOpen, "("})
+	case reflect.Uintptr:
+		// uintptr is almost always an address, so use hex like the pointers
+		ts = append(ts, tz.typeName(v.Type().Name()), tz.token(ParenOpen, "("))
 		t.Type = Number
-		t.Literal = fmt.Sprintf("%v", v.Interface())
-		t.Literal = t.Literal[1 : len(t.Literal)-1]
-		ts = append(ts, t, &Token{ParenClose, ")"})
+		t.Literal = fmt.Sprintf("0x%x", v.Uint())
+		ts = append(ts, t, tz.token(ParenClose, ")"))
+
+	case reflect.Complex64:
+		ts = append(ts, tz.typeName(v.Type().Name()), tz.token(ParenOpen, "("))
+		ts = append(ts, tz.tokenizeComplex(v.Complex(), 32)...)
+		ts = append(ts, tz.token(ParenClose, ")"))
 
 	case reflect.Complex128:
-		t.Type = Number
-		t.Literal = fmt.Sprintf("%v", v.Interface())
-		t.Literal = t.Literal[1 : len(t.Literal)-1]
-		ts = append(ts, t)
+		ts = append(ts, tz.tokenizeComplex(v.Complex(), 64)...)
 	}
 
 	return ts
 }
 
-func tokenizeRune(t *Token, r rune) *Token {
+// formatNumber formats the underlying value of v, so that the String method of the type won't be called
+func formatNumber(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32:
+		return fmt.Sprintf("%v", float32(v.Float()))
+	case reflect.Float64:
+		return fmt.Sprintf("%v", v.Float())
+	default:
+		return strconv.FormatUint(v.Uint(), 10)
+	}
+}
+
+// tokenizeComplex builds the literal from the real and imaginary parts,
+// if any of them is NaN or Inf it will use the builtin complex function to construct the value.
+func (tz *Tokenizer) tokenizeComplex(c complex128, bitSize int) []*Token {
+	r, i := real(c), imag(c)
+
+	if isSpecialFloat(r) || isSpecialFloat(i) {
+		ts := []*Token{tz.token(Func, "complex"), tz.token(ParenOpen, "(")}
+		ts = append(ts, tz.tokenizeFloat(r, bitSize)...)
+		ts = append(ts, tz.token(InlineComma, ","))
+		ts = append(ts, tz.tokenizeFloat(i, bitSize)...)
+		return append(ts, tz.token(ParenClose, ")"))
+	}
+
+	im := strconv.FormatFloat(i, 'g', -1, bitSize)
+	if im[0] != '-' {
+		im = "+" + im
+	}
+
+	return []*Token{tz.token(Number, strconv.FormatFloat(r, 'g', -1, bitSize)+im+"i")}
+}
+
+func (tz *Tokenizer) tokenizeFloat(f float64, bitSize int) []*Token {
+	switch {
+	case math.IsNaN(f):
+		return []*Token{tz.token(Func, "math.NaN"), tz.token(ParenOpen, "("), tz.token(ParenClose, ")")}
+	case math.IsInf(f, 0):
+		sign := "1"
+		if f < 0 {
+			sign = "-1"
+		}
+		return []*Token{tz.token(Func, "math.Inf"), tz.token(ParenOpen, "("), tz.token(Number, sign), tz.token(ParenClose, ")")}
+	}
+	return []*Token{tz.token(Number, strconv.FormatFloat(f, 'g', -1, bitSize))}
+}
+
+func isSpecialFloat(f float64) bool {
+	return math.IsNaN(f) || math.IsInf(f, 0)
+}
+
+// tokenizeRune escapes the combining and non-printable runes, because they are invisible or merged with the quote
+func (tz *Tokenizer) tokenizeRune(t *Token, r rune) *Token {
 	t.Type = Rune
-	t.Literal = fmt.Sprintf("'%s'", string(r))
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me) || !unicode.IsPrint(r):
+		if r > 0xffff {
+			t.Literal = fmt.Sprintf(`'\U%08x'`, r)
+		} else {
+			t.Literal = fmt.Sprintf(`'\u%04x'`, r)
+		}
+	default:
+		t.Literal = strconv.QuoteRune(r)
+	}
 	return t
 }
 
-func tokenizeByte(t *Token, b byte) []*Token {
-	ts := []*Token{typeName("byte"), {ParenOpen, "("}}
-	if unicode.IsGraphic(rune(b)) {
-		ts = append(ts, &Token{Byte, fmt.Sprintf("'%s'", string(b))})
+func (tz *Tokenizer) tokenizeByte(t *Token, b byte) []*Token {
+	ts := []*Token{tz.typeName("byte"), tz.token(ParenOpen, "(")}
+	if !BytesAsHex && b < utf8.RuneSelf && unicode.IsGraphic(rune(b)) {
+		ts = append(ts, tz.token(Byte, strconv.QuoteRune(rune(b))))
 	} else {
-		ts = append(ts, &Token{Byte, fmt.Sprintf("0x%x", b)})
+		ts = append(ts, tz.token(Byte, fmt.Sprintf("0x%x", b)))
 	}
-	return append(ts, &Token{ParenClose, ")"})
+	return append(ts, tz.token(ParenClose, ")"))
 }
 
-func tokenizeTime(t time.Time) []*Token {
-	ext := GetPrivateFieldByName(reflect.ValueOf(t), "ext").Int()
-	ts := []*Token{{Func, "gop.Time"}, {ParenOpen, "("}}
-	ts = append(ts, &Token{String, t.Format(time.RFC3339Nano)})
-	ts = append(ts, &Token{InlineComma, ","}, &Token{Number, fmt.Sprintf("%d", ext)}, &Token{ParenClose, ")"})
+func (tz *Tokenizer) tokenizeTime(t time.Time) []*Token {
+	ext := int64(0)
+	if ReadPrivate {
+		ext = GetPrivateFieldByName(reflect.ValueOf(t), "ext").Int()
+	}
+	ts := []*Token{tz.token(Func, "gop.Time"), tz.token(ParenOpen, "(")}
+	ts = append(ts, tz.token(String, t.Format(time.RFC3339Nano)))
+	ts = append(ts, tz.token(InlineComma, ","), tz.token(Number, fmt.Sprintf("%d", ext)), tz.token(ParenClose, ")"))
 	return ts
 }
 
-func tokenizeDuration(d time.Duration) []*Token {
+func (tz *Tokenizer) tokenizeDuration(d time.Duration) []*Token {
 	ts := []*Token{}
-	ts = append(ts, typeName("gop.Duration"), &Token{ParenOpen, "("})
-	ts = append(ts, &Token{String, d.String()})
-	ts = append(ts, &Token{ParenClose, ")"})
+	ts = append(ts, tz.typeName("gop.Duration"), tz.token(ParenOpen, "("))
+	ts = append(ts, tz.token(String, d.String()))
+	ts = append(ts, tz.token(ParenClose, ")"))
 	return ts
 }
 
-func tokenizeString(v reflect.Value) []*Token {
-	s := v.String()
-	ts := []*Token{{String, s}}
-	if v.Len() >= LongStringLen {
-		ts = append(ts, &Token{Comment, fmt.Sprintf("/* len=%d */", len(s))})
+var stringType = reflect.TypeOf("")
+
+func (tz *Tokenizer) tokenizeMapKey(sn seen, p path, k reflect.Value) []*Token {
+	e := k
+	if e.Kind() == reflect.Interface {
+		e = e.Elem()
+	}
+
+	if MapKeyPreview > 0 && !tz.NoTruncate && e.Kind() == reflect.String {
+		if _, has := tz.tokenizeSpecial(e); !has {
+			ts := tz.previewString(e.String(), MapKeyPreview)
+			if e.Type() == stringType {
+				return ts
+			}
+			// keep the named type, such as myKey("..."), the comment of the preview stays at the end
+			return append([]*Token{tz.typeName(e.Type().String()), tz.token(ParenOpen, "("), ts[0],
+				tz.token(ParenClose, ")")}, ts[1:]...)
+		}
+	}
+
+	return tz.tokenize(sn, p, k)
+}
+
+func (tz *Tokenizer) tokenizeString(v reflect.Value) []*Token {
+	n := StringPreview
+	if tz.NoTruncate {
+		n = 0
+	}
+	return tz.previewString(v.String(), n)
+}
+
+// previewString truncates s to its head and tail if it has more than n chars, 0 means no limit
+func (tz *Tokenizer) previewString(s string, n int) []*Token {
+	if rs := []rune(s); n > 0 && len(rs) > n {
+		head := string(rs[:n/2])
+		tail := string(rs[len(rs)-n/2:])
+		return []*Token{tz.token(String, head+"..."+tail),
+			tz.comment(fmt.Sprintf("truncated len=%d", len(rs)))}
+	}
+
+	ts := []*Token{tz.token(String, s)}
+	if len(s) >= LongStringLen {
+		ts = append(ts, tz.comment(fmt.Sprintf("len=%d", len(s))))
 	}
 	return ts
 }
 
-func tokenizeBytes(data []byte) []*Token {
+func (tz *Tokenizer) tokenizeBytes(data []byte) []*Token {
 	ts := []*Token{}
 
 	if utf8.Valid(data) {
 		s := string(data)
-		ts = append(ts, typeName("[]byte"), &Token{ParenOpen, "("})
-		ts = append(ts, &Token{String, s})
-		ts = append(ts, &Token{ParenClose, ")"})
+		ts = append(ts, tz.typeName("[]byte"), tz.token(ParenOpen, "("))
+		ts = append(ts, tz.token(String, s))
+		ts = append(ts, tz.token(ParenClose, ")"))
 	} else {
-		ts = append(ts, &Token{Func, "gop.Base64"}, &Token{ParenOpen, "("})
-		ts = append(ts, &Token{String, base64.StdEncoding.EncodeToString(data)})
-		ts = append(ts, &Token{ParenClose, ")"})
+		ts = append(ts, tz.token(Func, "gop.Base64"), tz.token(ParenOpen, "("))
+		ts = append(ts, tz.token(String, base64.StdEncoding.EncodeToString(data)))
+		ts = append(ts, tz.token(ParenClose, ")"))
 	}
 	if len(data) >= LongBytesLen {
-		ts = append(ts, &Token{Comment, fmt.Sprintf("/* len=%d */", len(data))})
+		ts = append(ts, tz.comment(fmt.Sprintf("len=%d", len(data))))
 	}
 	return ts
 }
 
-func tokenizePtr(sn seen, p path, v reflect.Value) []*Token {
+// tokenizeRunes renders the runes as a conversion from string, such as []rune("abc")
+func (tz *Tokenizer) tokenizeRunes(data []rune) []*Token {
+	ts := []*Token{tz.typeName("[]rune"), tz.token(ParenOpen, "("), tz.token(String, string(data)),
+		tz.token(ParenClose, ")")}
+	if len(data) >= LongStringLen {
+		ts = append(ts, tz.comment(fmt.Sprintf("len=%d", len(data))))
+	}
+	return ts
+}
+
+// graphicRunes returns true if all the runes are graphic, the other runes are clearer as rune literals
+func graphicRunes(data []rune) bool {
+	for _, r := range data {
+		if !unicode.IsGraphic(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (tz *Tokenizer) tokenizePtr(sn seen, p path, v reflect.Value) []*Token {
 	ts := []*Token{}
 
 	if v.Elem().Kind() == reflect.Invalid {
 		ts = append(ts,
-			&Token{ParenOpen, "("}, typeName(v.Type().String()), &Token{ParenClose, ")"},
-			&Token{ParenOpen, "("}, &Token{Nil, "nil"}, &Token{ParenClose, ")"})
+			tz.token(ParenOpen, "("), tz.typeName(v.Type().String()), tz.token(ParenClose, ")"),
+			tz.token(ParenOpen, "("), tz.token(Nil, "nil"), tz.token(ParenClose, ")"))
 		return ts
 	}
 
 	fn := false
 
 	switch v.Elem().Kind() {
+	case reflect.Func, reflect.Chan:
+		// the func or chan can't be rebuilt from its address, so only the type is kept as new(T),
+		// it's exact when the func or chan is nil, otherwise the address is kept as a comment.
+		ts = append(ts, tz.token(Func, "new"), tz.token(ParenOpen, "("),
+			tz.typeName(v.Type().Elem().String()), tz.token(ParenClose, ")"))
+		if !v.Elem().IsNil() {
+			ts = append(ts, tz.addr(tz.comment(fmt.Sprintf("0x%x", v.Elem().Pointer()))))
+		}
+		return ts
 	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
 		if _, ok := v.Elem().Interface().([]byte); ok {
 			fn = true
+		} else if _, ok := tz.tokenizeText(v.Elem()); ok {
+			fn = true
 		}
 	default:
 		fn = true
 	}
 
 	if fn {
-		ts = append(ts, &Token{Func, "gop.Ptr"}, &Token{ParenOpen, "("})
-		ts = append(ts, tokenize(sn, p, v.Elem())...)
-		ts = append(ts, &Token{ParenClose, ")"}, &Token{Dot, "."}, &Token{ParenOpen, "("},
-			typeName(v.Type().String()), &Token{ParenClose, ")"})
+		ts = append(ts, tz.token(Func, "gop.Ptr"), tz.token(ParenOpen, "("))
+		ts = append(ts, tz.tokenize(sn, p, v.Elem())...)
+		ts = append(ts, tz.token(ParenClose, ")"), tz.token(Dot, "."), tz.token(ParenOpen, "("),
+			tz.typeName(v.Type().String()), tz.token(ParenClose, ")"))
 	} else {
-		ts = append(ts, &Token{And, "&"})
-		ts = append(ts, tokenize(sn, p, v.Elem())...)
+		ts = append(ts, tz.token(And, "&"))
+		ts = append(ts, tz.tokenize(sn, p, v.Elem())...)
 	}
 
 	return ts
 }
 
-func tokenizeJSON(v reflect.Value) ([]*Token, bool) {
+func (tz *Tokenizer) tokenizeJSON(v reflect.Value) ([]*Token, bool) {
 	var jv interface{}
 	ts := []*Token{}
 	s := ""
@@ -474,37 +1365,86 @@ func tokenizeJSON(v reflect.Value) ([]*Token, bool) {
 		if err != nil {
 			return nil, false
 		}
-		ts = append(ts, &Token{Func, "gop.JSONStr"})
+		ts = append(ts, tz.token(Func, "gop.JSONStr"))
 	} else if b, ok := v.Interface().([]byte); ok {
 		err := json.Unmarshal(b, &jv)
 		if err != nil {
 			return nil, false
 		}
 		s = string(b)
-		ts = append(ts, &Token{Func, "gop.JSONBytes"})
+		ts = append(ts, tz.token(Func, "gop.JSONBytes"))
 	}
 
 	_, isObj := jv.(map[string]interface{})
 	_, isArr := jv.(map[string]interface{})
 
 	if isObj || isArr {
-		ts = append(ts, &Token{ParenOpen, "("})
-		ts = append(ts, Tokenize(jv)...)
-		ts = append(ts, &Token{InlineComma, ","},
-			&Token{String, s}, &Token{ParenClose, ")"})
+		ts = append(ts, tz.token(ParenOpen, "("))
+		ts = append(ts, tz.tokenize(seen{}, []interface{}{}, reflect.ValueOf(jv))...)
+		ts = append(ts, tz.token(InlineComma, ","),
+			tz.token(String, s), tz.token(ParenClose, ")"))
 		return ts, true
 	}
 
 	return nil, false
 }
 
-func typeName(t string) *Token {
+// tokenizeJSONView tokenizes the next JSON value of d, the keys of objects keep their order in the JSON
+func (tz *Tokenizer) tokenizeJSONView(d *json.Decoder) []*Token {
+	t, _ := d.Token()
+
+	// d uses json.Number, so the large integers won't be rounded as float64
+	if n, ok := t.(json.Number); ok {
+		return []*Token{tz.token(Number, n.String())}
+	}
+
+	isObj := t == json.Delim('{')
+	if !isObj && t != json.Delim('[') {
+		return tz.tokenize(seen{}, path{}, reflect.ValueOf(t))
+	}
+
+	n := 0
+	body := []*Token{}
+	for ; d.More(); n++ {
+		if isObj {
+			key, _ := d.Token()
+			body = append(body, tz.token(MapKey, ""))
+			body = append(body, tz.tokenizeString(reflect.ValueOf(key))...)
+			body = append(body, tz.token(Colon, ":"))
+		} else {
+			body = append(body, tz.token(SliceItem, ""))
+		}
+		body = append(body, tz.tokenizeJSONView(d)...)
+		body = append(body, tz.token(Comma, ","))
+	}
+	_, _ = d.Token()
+
+	ts := []*Token{}
+	if isObj {
+		ts = append(ts, tz.typeName("gop.Obj"))
+	} else {
+		ts = append(ts, tz.typeName("gop.Arr"))
+	}
+	if n > 1 {
+		ts = append(ts, tz.comment(fmt.Sprintf("len=%d", n)))
+	}
+	if isObj {
+		ts = append(ts, tz.token(MapOpen, "{"))
+		ts = append(ts, body...)
+		return append(ts, tz.token(MapClose, "}"))
+	}
+	ts = append(ts, tz.token(SliceOpen, "{"))
+	ts = append(ts, body...)
+	return append(ts, tz.token(SliceClose, "}"))
+}
+
+func (tz *Tokenizer) typeName(t string) *Token {
 	switch t {
 	case "map[string]interface {}":
-		return &Token{TypeName, "gop.Obj"}
+		return tz.token(TypeName, "gop.Obj")
 	case "[]interface {}":
-		return &Token{TypeName, "gop.Arr"}
+		return tz.token(TypeName, "gop.Arr")
 	default:
-		return &Token{TypeName, t}
+		return tz.token(TypeName, t)
 	}
 }
@@ -3,10 +3,50 @@ package gop
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"unsafe"
 )
 
+// Enum is the constraint of the types that can be registered via RegisterEnum
+type Enum interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+var enums = struct {
+	sync.RWMutex
+	names map[reflect.Type]map[interface{}]string
+}{names: map[reflect.Type]map[interface{}]string{}}
+
+// RegisterEnum makes the values of type T render as their constant names, such as:
+//
+//     gop.RegisterEnum(map[Status]string{StatusActive: "StatusActive"})
+//
+// Then Status(1) will be rendered as StatusActive/* 1 */, the unmapped values will still be rendered as numbers.
+func RegisterEnum[T Enum](names map[T]string) {
+	list := map[interface{}]string{}
+	for k, name := range names {
+		list[k] = name
+	}
+
+	enums.Lock()
+	defer enums.Unlock()
+	enums.names[reflect.TypeOf(T(0))] = list
+}
+
+func enumName(v reflect.Value) (string, bool) {
+	enums.RLock()
+	defer enums.RUnlock()
+
+	names, has := enums.names[v.Type()]
+	if !has {
+		return "", false
+	}
+	name, has := names[v.Interface()]
+	return name, has
+}
+
 // GetPrivateField via field index
 // TODO: we can use a LRU cache for the copy of the values, but it might be trivial for just testing.
 func GetPrivateField(v reflect.Value, i int) reflect.Value {
@@ -32,6 +72,91 @@ func GetPrivateFieldByName(v reflect.Value, name string) reflect.Value {
 	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
 }
 
+// OrderedKeys can be implemented by a map type to control the order of its keys in the output,
+// the existing keys of the map that are not in the list will follow in the sorted order.
+type OrderedKeys interface {
+	Keys() []interface{}
+}
+
+// mapKeys returns the keys of the map v in the order of OrderedKeys if v implements it,
+// or else in the order of sortMapKeys.
+func mapKeys(sn seen, v reflect.Value) []reflect.Value {
+	ok, is := v.Interface().(OrderedKeys)
+	if !is {
+		return sortMapKeys(sn, v)
+	}
+
+	keys := []reflect.Value{}
+	listed := map[interface{}]bool{}
+	for _, k := range ok.Keys() {
+		kv := reflect.ValueOf(k)
+		kt := v.Type().Key()
+		// the conversion between different kinds, such as int to string, would change the key
+		if !kv.IsValid() || !kv.Type().AssignableTo(kt) && (kv.Kind() != kt.Kind() || !kv.Type().ConvertibleTo(kt)) {
+			continue
+		}
+		kv = kv.Convert(kt)
+		if listed[kv.Interface()] || !v.MapIndex(kv).IsValid() {
+			continue
+		}
+		listed[kv.Interface()] = true
+		keys = append(keys, kv)
+	}
+
+	for _, k := range sortMapKeys(sn, v) {
+		if !listed[k.Interface()] {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// sortMapKeys returns the keys of the map v in a stable order.
+// Pointer and struct keys are sorted via the dumps of the key and value,
+// because the address is volatile and the struct may contains pointers.
+// The sn is the seen of the tokenization that v belongs to, it's used to stop the dumps at circular references.
+func sortMapKeys(sn seen, v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+
+	if k := v.Type().Key().Kind(); k != reflect.Ptr && k != reflect.Struct {
+		sort.Slice(keys, func(i, j int) bool {
+			return compare(keys[i].Interface(), keys[j].Interface()) < 0
+		})
+		return keys
+	}
+
+	type entry struct {
+		key  reflect.Value
+		dump string
+	}
+
+	list := make([]entry, len(keys))
+	for i, k := range keys {
+		list[i] = entry{k, dumpMapEntry(sn, v, k)}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].dump < list[j].dump
+	})
+
+	for i, e := range list {
+		keys[i] = e.key
+	}
+	return keys
+}
+
+// dumpMapEntry dumps the key k and its value in the map v with a copy of sn,
+// so the values that are being tokenized, such as v itself, will be rendered as circular references.
+func dumpMapEntry(sn seen, v, k reflect.Value) string {
+	c := seen{}
+	for ptr, p := range sn {
+		c[ptr] = p
+	}
+
+	tz := &Tokenizer{NoTruncate: true}
+	key := Format(tz.tokenize(c, path{}, k), ThemeNone)
+	return key + ":" + Format(tz.tokenize(c, path{}, v.MapIndex(k)), ThemeNone)
+}
+
 // compare returns the float value of x minus y
 func compare(x, y interface{}) int {
 	return strings.Compare(fmt.Sprintf("%#v", x), fmt.Sprintf("%#v", y))
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"math"
 	"reflect"
 	"strings"
 	"time"
@@ -24,8 +25,12 @@ func SmartCompare(x, y interface{}) float64 {
 			return xVal.Convert(float64Type).Float() - yVal.Convert(float64Type).Float()
 		}
 
+		// the monotonic clock and the location don't matter for the same instant
 		if xt, ok := xVal.Interface().(time.Time); ok {
 			if yt, ok := yVal.Interface().(time.Time); ok {
+				if xt.Equal(yt) {
+					return 0
+				}
 				return float64(xt.Sub(yt))
 			}
 		}
@@ -34,7 +39,67 @@ func SmartCompare(x, y interface{}) float64 {
 	return Compare(x, y)
 }
 
-// Compare returns the float value of x minus y
+// Compare returns the float value of x minus y.
+// The values are compared by their full dumps, the truncation options of gop don't affect the result.
 func Compare(x, y interface{}) float64 {
-	return float64(strings.Compare(gop.Plain(x), gop.Plain(y)))
+	tz := gop.NewTokenizer()
+	tz.NoTruncate = true
+	dx := gop.Format(tz.Tokenize(x), gop.ThemeNone)
+	dy := gop.Format(tz.Tokenize(y), gop.ThemeNone)
+	return float64(strings.Compare(dx, dy))
+}
+
+// HasNaN returns true if v or any value it holds, such as a struct field or a map value, is a NaN.
+func HasNaN(v interface{}) bool {
+	return hasNaN(map[visit]bool{}, reflect.ValueOf(v))
+}
+
+// visit is keyed by the type too, because a slice shares the address with its first element
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func hasNaN(sn map[visit]bool, v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return math.IsNaN(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return math.IsNaN(real(c)) || math.IsNaN(imag(c))
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+		key := visit{v.Pointer(), v.Type()}
+		if sn[key] {
+			return false
+		}
+		sn[key] = true
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		return hasNaN(sn, v.Elem())
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if hasNaN(sn, v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if hasNaN(sn, iter.Key()) || hasNaN(sn, iter.Value()) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if hasNaN(sn, v.Field(i)) {
+				return true
+			}
+		}
+	}
+	return false
 }
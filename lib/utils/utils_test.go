@@ -2,9 +2,11 @@ package utils_test
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
+	"github.com/ysmood/got/lib/gop"
 	"github.com/ysmood/got/lib/utils"
 )
 
@@ -33,6 +35,9 @@ func TestSmartCompare(t *testing.T) {
 		{ch, ch, 0.0},
 		{ch, ch2, -1.0},
 		{now.Add(time.Second), now, float64(time.Second)},
+		{now, now.In(time.FixedZone("X", 3600)), 0.0},
+		{now, now.Round(0).UTC(), 0.0},
+		{&now, now.UTC(), 0.0},
 		{circular, circular, 0.0},
 		{circular, 0, 1.0},
 		{map[int]interface{}{1: 1.0}, map[int]interface{}{1: 1}, 1.0},
@@ -53,3 +58,56 @@ func TestCompare(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestCompareTruncated(t *testing.T) {
+	gop.StringPreview = 4
+	gop.MapKeyPreview = 4
+	defer func() {
+		gop.StringPreview = 0
+		gop.MapKeyPreview = 0
+	}()
+
+	if utils.Compare("abc123def", "abc456def") == 0 {
+		t.Error("the truncated part of strings should be compared")
+	}
+	if utils.Compare(map[string]int{"abc123def": 1}, map[string]int{"abc456def": 1}) == 0 {
+		t.Error("the truncated part of map keys should be compared")
+	}
+}
+
+func TestHasNaN(t *testing.T) {
+	type node struct {
+		Next *node
+		Vals []float32
+		Tags map[string]interface{}
+		Arr  [1]complex128
+	}
+
+	circular := &node{}
+	circular.Next = circular
+
+	testCases := []struct {
+		v   interface{}
+		has bool
+	}{
+		{nil, false},
+		{1.0, false},
+		{math.NaN(), true},
+		{float32(math.NaN()), true},
+		{complex(1, math.NaN()), true},
+		{circular, false},
+		{node{Vals: []float32{1, float32(math.NaN())}}, true},
+		{node{Tags: map[string]interface{}{"a": math.NaN()}}, true},
+		{map[float64]int{math.NaN(): 1}, true},
+		{node{Arr: [1]complex128{complex(math.NaN(), 0)}}, true},
+		{&node{Next: &node{Vals: []float32{float32(math.NaN())}}}, true},
+		{node{Vals: []float32{1}, Tags: map[string]interface{}{"a": 1}}, false},
+	}
+	for i, c := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if utils.HasNaN(c.v) != c.has {
+				t.Error(c.v, c.has)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package got
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var _ Testable = &Mock{}
+
+// Mock is a Testable that records failures instead of failing a real test,
+// so that got can be used outside of "go test", such as in a fuzzing harness or property-based checks.
+type Mock struct {
+	lock     sync.Mutex
+	name     string
+	failed   bool
+	skipped  bool
+	logs     []string
+	failures []string
+	cleanups []func()
+}
+
+// MockTestable returns a Mock with the name
+func MockTestable(name string) *Mock {
+	return &Mock{name: name}
+}
+
+type mockStop struct{}
+
+// Check runs fn with a G that uses m as the Testable, it returns true if no failure is recorded during fn.
+// FailNow and SkipNow will stop fn, the functions registered by Cleanup will be called after fn.
+func (m *Mock) Check(fn func(g G)) (passed bool) {
+	m.lock.Lock()
+	count := len(m.failures)
+	m.lock.Unlock()
+
+	defer func() {
+		m.lock.Lock()
+		cleanups := m.cleanups
+		m.cleanups = nil
+		m.lock.Unlock()
+
+		for _, f := range cleanups {
+			f()
+		}
+
+		m.lock.Lock()
+		passed = count == len(m.failures)
+		m.lock.Unlock()
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(mockStop); !ok {
+				panic(r)
+			}
+		}
+	}()
+
+	fn(New(m))
+	return
+}
+
+// Failures returns the recorded failures, each one contains the logs before it.
+func (m *Mock) Failures() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]string{}, m.failures...)
+}
+
+// Name interface
+func (m *Mock) Name() string { return m.name }
+
+// Skipped interface
+func (m *Mock) Skipped() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.skipped
+}
+
+// Failed interface
+func (m *Mock) Failed() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.failed
+}
+
+// Cleanup interface, the functions will be called in last added, first called order after Mock.Check
+func (m *Mock) Cleanup(f func()) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.cleanups = append([]func(){f}, m.cleanups...)
+}
+
+// FailNow interface, it records the failure and stops the function passed to Mock.Check
+func (m *Mock) FailNow() {
+	m.Fail()
+	panic(mockStop{})
+}
+
+// Fail interface, it records the failure with the logs since the last failure
+func (m *Mock) Fail() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.failed = true
+	m.failures = append(m.failures, strings.Join(m.logs, "\n"))
+	m.logs = nil
+}
+
+// Helper interface
+func (m *Mock) Helper() {}
+
+// Logf interface
+func (m *Mock) Logf(format string, args ...interface{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.logs = append(m.logs, fmt.Sprintf(format, args...))
+}
+
+// SkipNow interface, it stops the function passed to Mock.Check
+func (m *Mock) SkipNow() {
+	m.lock.Lock()
+	m.skipped = true
+	m.lock.Unlock()
+	panic(mockStop{})
+}
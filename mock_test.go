@@ -0,0 +1,48 @@
+package got_test
+
+import (
+	"testing"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/got/lib/gop"
+)
+
+func TestMockTestable(t *testing.T) {
+	g := got.T(t)
+
+	m := got.MockTestable("prop")
+	g.Eq(m.Name(), "prop")
+
+	failed := 0
+	for i := 0; i < 10; i++ {
+		if !m.Check(func(g got.G) {
+			g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+			g.Lt(i, 8)
+		}) {
+			failed++
+		}
+	}
+	g.Eq(failed, 2)
+	g.True(m.Failed())
+	g.Eq(m.Failures(), []string{"8 ⦗not <⦘ 8", "9 ⦗not <⦘ 8"})
+
+	cleaned := false
+	g.False(m.Check(func(g got.G) {
+		g.Cleanup(func() { cleaned = true })
+		g.Log("stop")
+		g.FailNow()
+		g.Log("unreachable")
+	}))
+	g.True(cleaned)
+	g.Eq(m.Failures()[2], "stop\n")
+
+	g.True(m.Check(func(g got.G) {
+		g.SkipNow()
+		g.Fail()
+	}))
+	g.True(m.Skipped())
+
+	g.Panic(func() {
+		m.Check(func(g got.G) { panic("err") })
+	})
+}
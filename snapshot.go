@@ -0,0 +1,316 @@
+package got
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ysmood/got/lib/gop"
+)
+
+// SnapshotDir is the directory to store the snapshots, it's relative to the package of the test
+const SnapshotDir = ".got/snapshots"
+
+// snapshotUpdates is the number of the snapshots created or updated in this run
+var snapshotUpdates int64
+
+// SnapshotUpdates returns the number of the snapshots that are created or changed by the current run,
+// such as to check it in TestMain to fail the CI if any snapshot is stale.
+// Set the env var UPDATE_SNAPSHOTS to "never" to report the missing snapshots as failures instead of creating them.
+func SnapshotUpdates() int {
+	return int(atomic.LoadInt64(&snapshotUpdates))
+}
+
+// updateSnapshots returns true if the env var UPDATE_SNAPSHOTS asks to overwrite the existing snapshots
+func updateSnapshots() bool {
+	v := os.Getenv("UPDATE_SNAPSHOTS")
+	return v != "" && v != "never"
+}
+
+// frozenSnapshots returns true if the missing snapshots should fail instead of being created
+func frozenSnapshots() bool {
+	return os.Getenv("UPDATE_SNAPSHOTS") == "never"
+}
+
+var regUnsafeFileChars = regexp.MustCompile(`[^\w\-./]`)
+
+// Snapshot asserts that the dump of value equals the snapshot saved by the previous run of the test.
+// The snapshot will be saved to "SnapshotDir/{test name}/{name}.gop" if it doesn't exist,
+// set the env var UPDATE_SNAPSHOTS to overwrite the existing snapshots, or set it to "never" to fail on the missing ones.
+// The redactors mask the volatile values, such as IDs and timestamps, before writing and comparing, such as:
+//     g.Snapshot("user", user, gop.RedactPaths("ID", "CreatedAt"))
+func (g G) Snapshot(name string, value interface{}, redactors ...gop.Redactor) {
+	g.Helper()
+	g.SnapshotWith(name, value, GopSerializer{Redactors: redactors})
+}
+
+// Serializer converts the value to the content of the snapshot for SnapshotWith
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	// Ext returns the file extension of the snapshot, such as ".json"
+	Ext() string
+}
+
+// Deserializer is optional for a Serializer. If the Serializer implements it, the snapshots that are textually
+// different will be decoded and compared by their values, so only the semantic differences will be reported.
+type Deserializer interface {
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// SnapshotWith is similar with Snapshot, but it uses s to convert the value to the snapshot,
+// the snapshot will be saved to "SnapshotDir/{test name}/{name}{s.Ext()}", such as:
+//     g.SnapshotWith("user", user, got.JSONSerializer{})
+// The line endings are normalized to "\n" before writing and comparing.
+func (g G) SnapshotWith(name string, value interface{}, s Serializer) {
+	g.Helper()
+
+	data, err := s.Marshal(value)
+	g.Utils.err(err)
+	data = normalizeEOL(data)
+
+	p, saved, has := g.loadSnapshot(name, s.Ext(), data)
+	if !has {
+		return
+	}
+	saved = normalizeEOL(saved)
+	if bytes.Equal(data, saved) {
+		return
+	}
+
+	if d, ok := s.(Deserializer); ok {
+		x, errX := d.Unmarshal(data)
+		y, errY := d.Unmarshal(saved)
+		if errX == nil && errY == nil {
+			if reflect.DeepEqual(x, y) {
+				return
+			}
+			g.Assertions.err(AssertionSnapshot, p, x, y)
+			return
+		}
+	}
+
+	g.Assertions.err(AssertionSnapshot, p, string(data), string(saved))
+}
+
+var _ Serializer = GopSerializer{}
+
+// GopSerializer stores the snapshot as the plain gop dump of the value, it's the one used by Snapshot
+type GopSerializer struct {
+	// Redactors mask the volatile values before the dump
+	Redactors []gop.Redactor
+}
+
+// Marshal interface
+func (s GopSerializer) Marshal(v interface{}) ([]byte, error) {
+	tz := gop.NewTokenizer()
+	tz.Redact = func(p []interface{}) bool {
+		for _, r := range s.Redactors {
+			if r(p) {
+				return true
+			}
+		}
+		return false
+	}
+	return []byte(gop.Format(tz.Tokenize(v), gop.ThemeNone)), nil
+}
+
+// Ext interface
+func (s GopSerializer) Ext() string { return ".gop" }
+
+var _ Deserializer = JSONSerializer{}
+
+// JSONSerializer stores the snapshot as indented JSON, the snapshots are compared by the decoded values
+type JSONSerializer struct{}
+
+// Marshal interface
+func (s JSONSerializer) Marshal(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// Unmarshal interface
+func (s JSONSerializer) Unmarshal(data []byte) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// Ext interface
+func (s JSONSerializer) Ext() string { return ".json" }
+
+// SnapshotInline is similar with Snapshot, but the snapshots of a test and all its subtests are stored in one sidecar file
+// "SnapshotDir/{top-level test name}.snap" as a JSON object from the key of each snapshot to the dump of the value,
+// the key is the name prefixed with the subtest path, such as "sub/user" for g.SnapshotInline("user", u) in the subtest "sub".
+// The sidecar is cached until the file changes, the updates are merged into it, so the other entries are kept.
+// It's safe for the parallel subtests.
+func (g G) SnapshotInline(name string, value interface{}, redactors ...gop.Redactor) {
+	g.Helper()
+
+	data, _ := GopSerializer{Redactors: redactors}.Marshal(value)
+
+	top, sub := g.Name(), ""
+	if i := strings.Index(top, "/"); i > -1 {
+		top, sub = top[:i], top[i+1:]+"/"
+	}
+	p := filepath.Join(SnapshotDir, filepath.FromSlash(regUnsafeFileChars.ReplaceAllString(top, "_"))+".snap")
+	key := sub + name
+
+	saved, has := g.loadSidecar(p, key, string(data))
+	if !has {
+		return
+	}
+	saved = string(normalizeEOL([]byte(saved)))
+	if saved == string(data) {
+		return
+	}
+	g.Assertions.err(AssertionSnapshot, p+"#"+key, string(data), saved)
+}
+
+// sidecars caches the entries of each sidecar file of SnapshotInline
+var sidecars = struct {
+	sync.Mutex
+	files map[string]*sidecar
+}{files: map[string]*sidecar{}}
+
+// sidecar is the cached entries of a sidecar file, the stamp is the size and mod time of the file when it's cached
+type sidecar struct {
+	entries map[string]string
+	stamp   string
+}
+
+// fileStamp returns the size and mod time of the file p, it's empty if p doesn't exist
+func fileStamp(p string) string {
+	info, err := os.Stat(p)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d %d", info.Size(), info.ModTime().UnixNano())
+}
+
+// loadSidecar returns the saved entry of the key in the sidecar file p,
+// has is false if the data is merged into the sidecar as the new entry
+func (g G) loadSidecar(p, key, data string) (saved string, has bool) {
+	g.Helper()
+
+	sidecars.Lock()
+	defer sidecars.Unlock()
+
+	// reload the file if it's changed or removed since it's cached, such as by the previous run of "go test -count=2"
+	sc, loaded := sidecars.files[p]
+	if stamp := fileStamp(p); !loaded || sc.stamp != stamp {
+		sc = &sidecar{entries: map[string]string{}, stamp: stamp}
+		b, err := ioutil.ReadFile(p)
+		if err == nil {
+			err = json.Unmarshal(b, &sc.entries)
+		} else if os.IsNotExist(err) {
+			err = nil
+		}
+		g.Utils.err(err)
+		sidecars.files[p] = sc
+	}
+	entries := sc.entries
+
+	saved, has = entries[key]
+	if has && !updateSnapshots() {
+		return saved, true
+	}
+	if !has && frozenSnapshots() {
+		g.Assertions.err(AssertionSnapshotMissing, p+"#"+key)
+		return "", false
+	}
+	if !has || saved != data {
+		atomic.AddInt64(&snapshotUpdates, 1)
+	}
+
+	entries[key] = data
+	b, _ := json.MarshalIndent(entries, "", "  ")
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err == nil {
+		err = ioutil.WriteFile(p, append(b, '\n'), 0644)
+	}
+	g.Utils.err(err)
+	sc.stamp = fileStamp(p)
+	return "", false
+}
+
+// normalizeEOL converts the "\r\n" to "\n", so that the snapshots written on Windows still match on other platforms
+func normalizeEOL(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+}
+
+// SnapshotBytes is similar with Snapshot, but it stores the raw data to "SnapshotDir/{test name}/{name}.bin"
+// and compares it byte-for-byte, such as for images or encoded blobs. On mismatch, it reports the first
+// differing offset with a short hex window around it.
+func (g G) SnapshotBytes(name string, data []byte) {
+	g.Helper()
+
+	p, saved, has := g.loadSnapshot(name, ".bin", data)
+	if !has || bytes.Equal(data, saved) {
+		return
+	}
+
+	i := firstDiff(data, saved)
+	g.Assertions.err(AssertionSnapshotBytes, p, i, hexWindow(data, i), hexWindow(saved, i))
+}
+
+// loadSnapshot returns the saved snapshot of the name, has is false if the data is written as the new snapshot
+func (g G) loadSnapshot(name, ext string, data []byte) (p string, saved []byte, has bool) {
+	g.Helper()
+
+	p = filepath.Join(SnapshotDir, filepath.FromSlash(regUnsafeFileChars.ReplaceAllString(g.Name()+"/"+name, "_"))+ext)
+
+	_, err := os.Stat(p)
+	missing := os.IsNotExist(err)
+	if missing && frozenSnapshots() {
+		g.Assertions.err(AssertionSnapshotMissing, p)
+		return p, nil, false
+	}
+	if missing || updateSnapshots() {
+		old, _ := ioutil.ReadFile(p)
+		err := os.MkdirAll(filepath.Dir(p), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(p, data, 0644)
+		}
+		g.Utils.err(err)
+		if missing || !bytes.Equal(old, data) {
+			atomic.AddInt64(&snapshotUpdates, 1)
+		}
+		return p, nil, false
+	}
+
+	saved, err = ioutil.ReadFile(p)
+	g.Utils.err(err)
+	return p, saved, true
+}
+
+// firstDiff returns the offset of the first differing byte of x and y
+func firstDiff(x, y []byte) int {
+	i := 0
+	for ; i < len(x) && i < len(y) && x[i] == y[i]; i++ {
+	}
+	return i
+}
+
+// hexWindowSize is the number of bytes to show before and after the offset
+const hexWindowSize = 8
+
+// hexWindow returns the hex of b around the offset i, i should be no greater than len(b)
+func hexWindow(b []byte, i int) string {
+	start := i - hexWindowSize
+	if start < 0 {
+		start = 0
+	}
+	end := i + hexWindowSize
+	if end > len(b) {
+		end = len(b)
+	}
+	return hex.EncodeToString(b[start:end])
+}
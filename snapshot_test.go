@@ -0,0 +1,265 @@
+package got_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ysmood/got"
+	"github.com/ysmood/got/lib/diff"
+	"github.com/ysmood/got/lib/gop"
+)
+
+type user struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+	Tags      map[string]string
+}
+
+func TestSnapshot(t *testing.T) {
+	g := got.T(t)
+
+	u := user{g.RandStr(8), "jack", time.Now(), map[string]string{"ID": g.RandStr(8), "role": "admin"}}
+
+	g.Snapshot("user", u, gop.RedactPaths("ID", "CreatedAt"))
+	g.Snapshot("user", u, gop.RedactPaths("Tags.ID"), gop.RedactPaths("ID", "CreatedAt"))
+	g.Snapshot("int", 1)
+	g.SnapshotBytes("bin", []byte{0xff, 0x00, 0x01})
+	g.SnapshotWith("json", map[string]interface{}{"name": "jack", "tags": []string{"admin"}}, got.JSONSerializer{})
+}
+
+func TestSnapshotMismatch(t *testing.T) {
+	g := got.T(t)
+
+	dir := filepath.Join(got.SnapshotDir, "snapshot", "mock")
+	g.Cleanup(func() { _ = os.RemoveAll(filepath.Dir(dir)) })
+
+	m := got.MockTestable("snapshot/mock")
+	check := func(v interface{}) bool {
+		return m.Check(func(g got.G) {
+			g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, diff.ThemeNone)
+			g.Snapshot("a b", v)
+		})
+	}
+
+	g.True(check(1))
+	g.True(check(1))
+	g.False(check(2))
+	g.Eq(m.Failures()[0], "\n ⦗snapshot⦘ .got/snapshots/snapshot/mock/a_b.gop"+
+		" ⦗mismatch, set the env var to update it⦘ UPDATE_SNAPSHOTS=true\n\n"+
+		"@@ diff chunk @@\n1   - 2\n  1 + 1\n\n")
+
+	t.Setenv("UPDATE_SNAPSHOTS", "true")
+	g.True(check(2))
+	g.Nil(os.Setenv("UPDATE_SNAPSHOTS", ""))
+	g.True(check(2))
+
+	// the snapshot written on Windows
+	g.Nil(ioutil.WriteFile(filepath.Join(dir, "a_b.gop"), []byte("gop.Arr/* len=2 cap=2 */{\r\n    1,\r\n    2,\r\n}"), 0644))
+	g.True(check([]interface{}{1, 2}))
+
+	// the snapshot path is a directory
+	g.Nil(os.Remove(filepath.Join(dir, "a_b.gop")))
+	g.Nil(os.Mkdir(filepath.Join(dir, "a_b.gop"), 0755))
+	g.False(check(2))
+	g.Has(m.Failures()[1], "is a directory")
+}
+
+func TestSnapshotBytesMismatch(t *testing.T) {
+	g := got.T(t)
+
+	dir := filepath.Join(got.SnapshotDir, "snapshot_bytes")
+	g.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	m := got.MockTestable("snapshot_bytes")
+	check := func(data []byte) bool {
+		return m.Check(func(g got.G) {
+			g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+			g.SnapshotBytes("bin", data)
+		})
+	}
+
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	g.True(check(data))
+	g.True(check(data))
+
+	g.False(check([]byte("0123456789abcdefgh_jklmnopqrstuvwxyz")))
+	g.Eq(m.Failures()[0], " ⦗snapshot⦘ .got/snapshots/snapshot_bytes/bin.bin ⦗mismatch at offset⦘ 18"+
+		" ⦗actual⦘ 61626364656667685f6a6b6c6d6e6f70 ⦗expected⦘ 6162636465666768696a6b6c6d6e6f70")
+
+	g.False(check([]byte("0123")))
+	g.Eq(m.Failures()[1], " ⦗snapshot⦘ .got/snapshots/snapshot_bytes/bin.bin ⦗mismatch at offset⦘ 4"+
+		" ⦗actual⦘ 30313233 ⦗expected⦘ 303132333435363738396162")
+}
+
+func TestSnapshotWithMismatch(t *testing.T) {
+	g := got.T(t)
+
+	dir := filepath.Join(got.SnapshotDir, "snapshot_with")
+	g.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	m := got.MockTestable("snapshot_with")
+	check := func(v interface{}) bool {
+		return m.Check(func(g got.G) {
+			g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+			g.SnapshotWith("json", v, got.JSONSerializer{})
+		})
+	}
+
+	g.True(check(map[string]int{"a": 1, "b": 2}))
+
+	// the formatting of the saved snapshot doesn't matter
+	g.Nil(ioutil.WriteFile(filepath.Join(dir, "json.json"), []byte(`{"b":2,"a":1}`), 0644))
+	g.True(check(map[string]int{"a": 1, "b": 2}))
+
+	g.False(check(map[string]int{"a": 1, "b": 3}))
+	g.Eq(m.Failures()[0], " ⦗snapshot⦘ .got/snapshots/snapshot_with/json.json ⦗mismatch, set the env var to update it⦘ UPDATE_SNAPSHOTS=true\n\n"+
+		"gop.Obj/* len=2 */{\n    \"a\": float64(1),\n    \"b\": float64(3),\n}"+
+		"\n\n ⦗not ==⦘ \n\n"+
+		"gop.Obj/* len=2 */{\n    \"a\": float64(1),\n    \"b\": float64(2),\n}")
+
+	// only the changed lines of the decoded values
+	md := got.MockTestable("snapshot_with")
+	g.False(md.Check(func(g got.G) {
+		g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, diff.ThemeNone)
+		g.SnapshotWith("json", map[string]int{"a": 1, "b": 3}, got.JSONSerializer{})
+	}))
+	g.Has(md.Failures()[0], "@@ diff chunk @@\n2 2       \"a\": float64(1),\n3   -     \"b\": float64(3),")
+
+	// fallback to the text when the saved snapshot can't be decoded
+	g.Nil(ioutil.WriteFile(filepath.Join(dir, "json.json"), []byte(`{`), 0644))
+	g.False(check(1))
+	g.Eq(m.Failures()[1], " ⦗snapshot⦘ .got/snapshots/snapshot_with/json.json ⦗mismatch, set the env var to update it⦘ UPDATE_SNAPSHOTS=true\n\"1\" ⦗not ==⦘ \"{\"")
+
+	g.False(check(make(chan int)))
+	g.Has(m.Failures()[2], "unsupported type")
+}
+
+func TestSnapshotInline(t *testing.T) {
+	g := got.T(t)
+
+	u := user{g.RandStr(8), "jack", time.Now(), map[string]string{"role": "admin"}}
+	g.SnapshotInline("user", u, gop.RedactPaths("ID", "CreatedAt"))
+	g.SnapshotInline("int", 1)
+
+	for i := 0; i < 10; i++ {
+		i := i
+		t.Run(fmt.Sprintf("sub%d", i), func(t *testing.T) {
+			t.Parallel()
+			got.T(t).SnapshotInline("n", i)
+		})
+	}
+}
+
+func TestSnapshotInlineMismatch(t *testing.T) {
+	g := got.T(t)
+
+	p := filepath.Join(got.SnapshotDir, "snapshot_inline.snap")
+	g.Cleanup(func() { _ = os.Remove(p) })
+	read := func() string {
+		b, err := ioutil.ReadFile(p)
+		g.E(err)
+		return string(b)
+	}
+
+	m := got.MockTestable("snapshot_inline/mock")
+	check := func(name string, v interface{}) bool {
+		return m.Check(func(g got.G) {
+			g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+			g.SnapshotInline(name, v)
+		})
+	}
+
+	g.True(check("a", 1))
+	g.True(check("b", "x\ny"))
+	g.True(check("a", 1))
+	g.Eq(read(), "{\n  \"mock/a\": \"1\",\n  \"mock/b\": \"`x\\ny`\"\n}\n")
+
+	g.False(check("a", 2))
+	g.Eq(m.Failures()[0], " ⦗snapshot⦘ .got/snapshots/snapshot_inline.snap#mock/a"+
+		" ⦗mismatch, set the env var to update it⦘ UPDATE_SNAPSHOTS=true\n\"2\" ⦗not ==⦘ \"1\"")
+
+	t.Setenv("UPDATE_SNAPSHOTS", "true")
+	g.True(check("a", 2))
+	g.Nil(os.Setenv("UPDATE_SNAPSHOTS", ""))
+	g.True(check("a", 2))
+	g.Eq(read(), "{\n  \"mock/a\": \"2\",\n  \"mock/b\": \"`x\\ny`\"\n}\n")
+
+	// a test that isn't a subtest
+	m = got.MockTestable("snapshot_inline")
+	g.True(check("c", 3))
+	g.Has(read(), `"c": "3"`)
+	g.Has(read(), `"mock/a": "2"`)
+
+	// the cache is reloaded when the file is removed or rewritten
+	g.Nil(os.Remove(p))
+	g.True(check("c", 4))
+	g.Eq(read(), "{\n  \"c\": \"4\"\n}\n")
+	g.Nil(ioutil.WriteFile(p, []byte(`{"c": "5", "other": "1"}`), 0644))
+	g.False(check("c", 4))
+	g.Has(m.Failures()[0], `"4" ⦗not ==⦘ "5"`)
+
+	// an invalid sidecar
+	bad := filepath.Join(got.SnapshotDir, "snapshot_inline_bad.snap")
+	g.Cleanup(func() { _ = os.Remove(bad) })
+	g.Nil(ioutil.WriteFile(bad, []byte("{"), 0644))
+	m = got.MockTestable("snapshot_inline_bad")
+	g.False(check("a", 1))
+	g.Has(m.Failures()[0], "unexpected end of JSON input")
+
+	// the sidecar path is a directory
+	dir := filepath.Join(got.SnapshotDir, "snapshot_inline_dir.snap")
+	g.Cleanup(func() { _ = os.RemoveAll(dir) })
+	g.Nil(os.MkdirAll(dir, 0755))
+	m = got.MockTestable("snapshot_inline_dir")
+	g.False(check("a", 1))
+	g.Has(m.Failures()[0], "is a directory")
+}
+
+func TestSnapshotUpdates(t *testing.T) {
+	g := got.T(t)
+
+	g.Cleanup(func() {
+		_ = os.RemoveAll(filepath.Join(got.SnapshotDir, "snapshot_updates"))
+		_ = os.Remove(filepath.Join(got.SnapshotDir, "snapshot_updates.snap"))
+	})
+
+	m := got.MockTestable("snapshot_updates/mock")
+	check := func(v interface{}) bool {
+		return m.Check(func(g got.G) {
+			g.ErrorHandler = got.NewDefaultAssertionError(gop.ThemeNone, nil)
+			g.Snapshot("a", v)
+			g.SnapshotInline("a", v)
+		})
+	}
+
+	count := got.SnapshotUpdates()
+	g.True(check(1))
+	g.Eq(got.SnapshotUpdates()-count, 2)
+	g.True(check(1))
+	g.Eq(got.SnapshotUpdates()-count, 2)
+
+	t.Setenv("UPDATE_SNAPSHOTS", "true")
+	g.True(check(1))
+	g.Eq(got.SnapshotUpdates()-count, 2)
+	g.True(check(2))
+	g.Eq(got.SnapshotUpdates()-count, 4)
+
+	g.Nil(os.Setenv("UPDATE_SNAPSHOTS", "never"))
+	g.True(check(2))
+	m = got.MockTestable("snapshot_updates/missing")
+	g.False(check(2))
+	g.Eq(m.Failures(), []string{
+		" ⦗snapshot⦘ .got/snapshots/snapshot_updates/missing/a.gop" +
+			" ⦗doesn't exist, it won't be created when the env var is⦘ UPDATE_SNAPSHOTS=never",
+		" ⦗snapshot⦘ .got/snapshots/snapshot_updates.snap#missing/a" +
+			" ⦗doesn't exist, it won't be created when the env var is⦘ UPDATE_SNAPSHOTS=never",
+	})
+	g.Eq(got.SnapshotUpdates()-count, 4)
+	_, err := os.Stat(filepath.Join(got.SnapshotDir, "snapshot_updates", "missing"))
+	g.True(os.IsNotExist(err))
+}
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
+	mrand "math/rand"
 	"mime"
 	"net"
 	"net/http"
@@ -17,8 +19,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -31,8 +35,19 @@ type Context struct {
 // Utils for commonly used methods
 type Utils struct {
 	Testable
+
+	src *randSource
+}
+
+// randSource is the random source of the helpers such as RandStr, it's shared by the copies of a G
+type randSource struct {
+	lock sync.Mutex
+	r    *mrand.Rand
 }
 
+// globalRandSource is used by the Utils that aren't created by New
+var globalRandSource = &randSource{}
+
 // Fatal is the same as testing.common.Fatal
 func (ut Utils) Fatal(args ...interface{}) {
 	ut.Helper()
@@ -123,6 +138,59 @@ func (ut Utils) PanicAfter(d time.Duration) (cancel func()) {
 	})
 }
 
+// WaitGroup calls fn with the add function, each function passed to add will run in a new goroutine,
+// then it waits for all of them to finish. If they don't finish within the timeout, the test fails
+// with the number of the goroutines that are still running and their stacks, instead of hanging until
+// "go test -timeout" dumps all the goroutines. The goroutines keep running after the timeout.
+func (ut Utils) WaitGroup(timeout time.Duration, fn func(add func(f func()))) {
+	ut.Helper()
+
+	wg := &sync.WaitGroup{}
+	running := int64(0)
+	fn(func(f func()) {
+		wg.Add(1)
+		atomic.AddInt64(&running, 1)
+		go waitGroupWorker(wg, &running, f)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	tmr := time.NewTimer(timeout)
+	defer tmr.Stop()
+
+	select {
+	case <-done:
+	case <-tmr.C:
+		ut.Fatalf("[timeout] %d goroutines of WaitGroup didn't finish within %v\n\n%s",
+			atomic.LoadInt64(&running), timeout, waitGroupStacks())
+	}
+}
+
+// waitGroupWorker is a named function, so that the goroutines of WaitGroup can be found in the stacks
+func waitGroupWorker(wg *sync.WaitGroup, running *int64, f func()) {
+	defer wg.Done()
+	defer atomic.AddInt64(running, -1)
+	f()
+}
+
+// waitGroupStacks returns the stacks of the goroutines that are running waitGroupWorker
+func waitGroupStacks() string {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+
+	list := []string{}
+	for _, s := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(s, "got.waitGroupWorker(") {
+			list = append(list, s)
+		}
+	}
+	return strings.Join(list, "\n\n")
+}
+
 // Context that will be canceled after the test
 func (ut Utils) Context() Context {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -137,21 +205,98 @@ func (ut Utils) Timeout(d time.Duration) Context {
 	return Context{ctx, cancel}
 }
 
+// Seed sets the seed of the random source of the helpers such as RandStr and RandInt.
+// If it's not set, a random seed will be logged when a random helper is used for the first time,
+// so that a failed test can be reproduced by calling Seed with the logged seed.
+func (ut Utils) Seed(seed int64) {
+	src := ut.source()
+	src.lock.Lock()
+	defer src.lock.Unlock()
+	src.r = mrand.New(mrand.NewSource(seed))
+}
+
 // RandStr generates a random string with the specified length
-func (ut Utils) RandStr(l int) string {
+func (ut Utils) RandStr(l int) (s string) {
 	ut.Helper()
-	b := make([]byte, (l+1)/2)
-	_, err := rand.Read(b)
-	ut.err(err)
-	return hex.EncodeToString(b)[:l]
+	ut.random(func(r *mrand.Rand) {
+		b := make([]byte, (l+1)/2)
+		_, _ = r.Read(b)
+		s = hex.EncodeToString(b)[:l]
+	})
+	return
 }
 
 // RandInt generates a random integer within [min, max)
-func (ut Utils) RandInt(min, max int) int {
+func (ut Utils) RandInt(min, max int) (n int) {
 	ut.Helper()
-	n, err := rand.Int(rand.Reader, big.NewInt(int64(max-min)))
+	ut.random(func(r *mrand.Rand) {
+		n = r.Intn(max-min) + min
+	})
+	return
+}
+
+func (ut Utils) source() *randSource {
+	if ut.src == nil {
+		return globalRandSource
+	}
+	return ut.src
+}
+
+// random calls fn with the random source, the source is seeded randomly if Seed isn't called
+func (ut Utils) random(fn func(r *mrand.Rand)) {
+	ut.Helper()
+
+	src := ut.source()
+	src.lock.Lock()
+	defer src.lock.Unlock()
+
+	if src.r == nil {
+		n, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+		ut.err(err)
+		seed := n.Int64()
+		ut.Logf("[seed] the random seed is %d, call g.Seed(%d) to reproduce", seed, seed)
+		src.r = mrand.New(mrand.NewSource(seed))
+	}
+
+	fn(src.r)
+}
+
+// CaptureOutput runs fn with os.Stdout and os.Stderr redirected to pipes, and returns what fn writes to them.
+// The pipes are drained while fn is running, so a large output won't block fn.
+// The originals will be restored even if fn panics. Because os.Stdout and os.Stderr are global,
+// don't use it in parallel tests.
+func (ut Utils) CaptureOutput(fn func()) (stdout, stderr string) {
+	ut.Helper()
+
+	outR, outW, err := os.Pipe()
+	var errR, errW *os.File
+	if err == nil {
+		errR, errW, err = os.Pipe()
+	}
 	ut.err(err)
-	return int(n.Int64()) + min
+
+	read := func(r *os.File) <-chan string {
+		ch := make(chan string, 1)
+		go func() {
+			b, _ := ioutil.ReadAll(r)
+			_ = r.Close()
+			ch <- string(b)
+		}()
+		return ch
+	}
+	outCh, errCh := read(outR), read(errR)
+
+	origOut, origErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outW, errW
+	defer func() {
+		os.Stdout, os.Stderr = origOut, origErr
+		_ = outW.Close()
+		_ = errW.Close()
+		stdout, stderr = <-outCh, <-errCh
+	}()
+
+	fn()
+	return
 }
 
 // Open a file. Override it if create is true. Directories will be auto-created.
@@ -2,10 +2,15 @@ package got_test
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -124,6 +129,93 @@ func TestHelper(t *testing.T) {
 	ut.Eq(m.msg, "test skip")
 }
 
+func TestSeed(t *testing.T) {
+	g := got.T(t)
+
+	gen := func(g got.G) (string, int) {
+		return g.RandStr(16), g.RandInt(0, 1000000)
+	}
+
+	a := got.T(t)
+	a.Seed(1)
+	b := got.T(t)
+	b.Seed(1)
+	g.Eq(a.RandStr(16), b.RandStr(16))
+
+	var s string
+	var n int
+	m := got.MockTestable("seed")
+	m.Check(func(g got.G) {
+		s, n = gen(g)
+		g.Fail()
+	})
+	ms := regexp.MustCompile(`^\[seed\] the random seed is (\d+), call g.Seed\(\d+\) to reproduce$`).
+		FindStringSubmatch(m.Failures()[0])
+	g.Len(ms, 2)
+
+	seed, err := strconv.ParseInt(ms[1], 10, 64)
+	g.E(err)
+	c := got.T(t)
+	c.Seed(seed)
+	cs, cn := gen(c)
+	g.Eq(cs, s)
+	g.Eq(cn, n)
+
+	// the Utils that isn't created by New
+	g.Lt(got.Utils{Testable: t}.RandInt(0, 1), 1)
+}
+
+func TestCaptureOutput(t *testing.T) {
+	g := got.T(t)
+
+	stdout, stderr := g.CaptureOutput(func() {
+		fmt.Println("out")
+		fmt.Fprint(os.Stderr, "err")
+	})
+	g.Eq(stdout, "out\n")
+	g.Eq(stderr, "err")
+
+	// larger than the buffer of the pipe
+	large := strings.Repeat("a", 1024*1024)
+	stdout, _ = g.CaptureOutput(func() {
+		fmt.Print(large)
+	})
+	g.Eq(len(stdout), len(large))
+
+	orig := os.Stdout
+	g.Panic(func() {
+		g.CaptureOutput(func() { panic("err") })
+	})
+	g.Eq(os.Stdout, orig)
+}
+
+func TestWaitGroup(t *testing.T) {
+	g := got.T(t)
+
+	count := int64(0)
+	g.WaitGroup(time.Second, func(add func(f func())) {
+		for i := 0; i < 10; i++ {
+			add(func() { atomic.AddInt64(&count, 1) })
+		}
+	})
+	g.Eq(count, int64(10))
+
+	block := make(chan struct{})
+	defer close(block)
+
+	m := got.MockTestable("wait_group")
+	g.False(m.Check(func(g got.G) {
+		g.WaitGroup(100*time.Millisecond, func(add func(f func())) {
+			add(func() {})
+			add(func() { <-block })
+		})
+	}))
+	failure := m.Failures()[0]
+	g.Has(failure, "[timeout] 1 goroutines of WaitGroup didn't finish within 100ms\n\ngoroutine ")
+	g.Has(failure, "TestWaitGroup")
+	g.Eq(strings.Count(failure, "got.waitGroupWorker("), 1)
+}
+
 func TestServe(t *testing.T) {
 	ut := setup(t)
 